@@ -9,6 +9,8 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/nacl/secretbox"
@@ -17,7 +19,6 @@ import (
 
 	sdk "github.com/bitmark-inc/bitmark-sdk-go"
 	"github.com/bitmark-inc/bitmark-sdk-go/encoding"
-	"github.com/bitmark-inc/bitmarkd/util"
 )
 
 type Version string
@@ -80,8 +81,12 @@ var (
 	ErrWrongNetwork          = errors.New("wrong network")
 	ErrInvalidSeed           = errors.New("invalid seed")
 	ErrInvalidRecoveryPhrase = errors.New("invalid recovery phrase")
-	ErrInvalidChecksum       = errors.New("invalid checksum")
+	ErrInvalidChecksum       = encoding.ErrInvalidChecksum
+	ErrInvalidAccountNumber  = errors.New("invalid account number")
 	ErrLangNotSupported      = errors.New("language not supported")
+	ErrAccountDestroyed      = errors.New("account destroyed")
+	ErrKeyWiped              = errors.New("key wiped")
+	ErrInvalidSignature      = errors.New("invalid signature")
 )
 
 type Account interface {
@@ -92,6 +97,7 @@ type Account interface {
 	AccountNumber() string
 	Bytes() []byte
 	Sign(message []byte) (signature []byte)
+	Destroy()
 }
 
 func New() (Account, error) {
@@ -115,22 +121,19 @@ func New() (Account, error) {
 		mode = mode ^ 0xf0
 	}
 	seed[15] = mode | seed[15]&0x0f
+	defer wipe(seed)
 
 	return NewAccountV2(seed)
 }
 
 func FromSeed(seedBase58Encoded string) (Account, error) {
-	s := encoding.FromBase58(seedBase58Encoded)
-
-	if len(s) != base58EncodedSeedV1Length && len(s) != base58EncodedseedCoreV2Length {
+	s, err := encoding.FromBase58Check(seedBase58Encoded, encoding.SHA3Checksum)
+	if err != nil {
 		return nil, ErrInvalidSeed
 	}
+	defer wipe(s)
 
-	data := s[:len(s)-seedChecksumLength]
-	digest := sha3.Sum256(data)
-	expectedChecksum := digest[:seedChecksumLength]
-	actualChecksum := s[len(s)-seedChecksumLength:]
-	if !bytes.Equal(expectedChecksum, actualChecksum) {
+	if len(s)+seedChecksumLength != base58EncodedSeedV1Length && len(s)+seedChecksumLength != base58EncodedseedCoreV2Length {
 		return nil, ErrInvalidSeed
 	}
 
@@ -149,15 +152,16 @@ func FromSeed(seedBase58Encoded string) (Account, error) {
 			return nil, ErrWrongNetwork
 		}
 
-		seed := s[seedHeaderLength+seedPrefixLength : len(s)-seedChecksumLength]
-		var core = new([32]byte)
+		seed := s[seedHeaderLength+seedPrefixLength:]
+		var core [seedCoreV1Length]byte
 		copy(core[:], seed)
+		defer wipe(core[:])
 
-		return NewAccountV1(core)
+		return NewAccountV1(&core)
 	case bytes.Equal(header, seedHeaderV2):
 		// parse network
 		var network sdk.Network
-		core := s[seedHeaderLength : len(s)-seedChecksumLength]
+		core := s[seedHeaderLength:]
 		mode := core[0]&0x80 | core[1]&0x40 | core[2]&0x20 | core[3]&0x10
 		switch mode {
 		case core[15] & 0xF0:
@@ -172,10 +176,7 @@ func FromSeed(seedBase58Encoded string) (Account, error) {
 			return nil, ErrWrongNetwork
 		}
 
-		checksumStart := len(s) - seedChecksumLength
-		seed := s[seedHeaderLength:checksumStart]
-
-		return NewAccountV2(seed)
+		return NewAccountV2(core)
 	default:
 		return nil, ErrInvalidSeed
 	}
@@ -193,10 +194,12 @@ func FromRecoveryPhrase(words []string, lang language.Tag) (Account, error) {
 		if err != nil {
 			return nil, err
 		}
+		defer wipe(b)
 
 		networkIndicator := b[0]
-		var core = new([32]byte)
+		var core [seedCoreV1Length]byte
 		copy(core[:], b[1:])
+		defer wipe(core[:])
 
 		var network sdk.Network
 		switch networkIndicator {
@@ -212,12 +215,13 @@ func FromRecoveryPhrase(words []string, lang language.Tag) (Account, error) {
 			return nil, ErrWrongNetwork
 		}
 
-		return NewAccountV1(core)
+		return NewAccountV1(&core)
 	case recoveryPhraseV2Length:
 		core, err := twelveWordsToBytes(words, dict)
 		if err != nil {
 			return nil, err
 		}
+		defer wipe(core)
 
 		// parse network
 		var network sdk.Network
@@ -241,6 +245,7 @@ func FromRecoveryPhrase(words []string, lang language.Tag) (Account, error) {
 		if err != nil {
 			return nil, err
 		}
+		defer wipe(core)
 
 		// parse network
 		var network sdk.Network
@@ -265,26 +270,42 @@ func FromRecoveryPhrase(words []string, lang language.Tag) (Account, error) {
 }
 
 type AccountV1 struct {
-	network  sdk.Network
-	seedCore *[32]byte
-	AuthKey  AuthKey
-	EncrKey  EncrKey
+	network   sdk.Network
+	seedCore  *[32]byte
+	AuthKey   AuthKey
+	EncrKey   EncrKey
+	destroyed bool
 }
 
+// NewAccountV1 keeps a copy of the seed core, the caller still owns seedCore
 func NewAccountV1(seedCore *[seedCoreV1Length]byte) (*AccountV1, error) {
+	if seedCore == nil {
+		return nil, ErrInvalidSeed
+	}
+
 	authEntropy := secretbox.Seal([]byte{}, authSeedCount[:], &seedNonce, seedCore)
+	defer wipe(authEntropy)
 	authKey, err := NewAuthKey(authEntropy)
 	if err != nil {
 		return nil, err
 	}
 
 	encrEntropy := secretbox.Seal([]byte{}, encrSeedCount[:], &seedNonce, seedCore)
+	defer wipe(encrEntropy)
 	encrKey, err := NewEncrKey(encrEntropy)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AccountV1{sdk.GetNetwork(), seedCore, authKey, encrKey}, nil
+	core := new([seedCoreV1Length]byte)
+	*core = *seedCore
+
+	return &AccountV1{
+		network:  sdk.GetNetwork(),
+		seedCore: core,
+		AuthKey:  authKey,
+		EncrKey:  encrKey,
+	}, nil
 }
 
 func (acct *AccountV1) Network() sdk.Network {
@@ -292,7 +313,12 @@ func (acct *AccountV1) Network() sdk.Network {
 }
 
 func (acct *AccountV1) Seed() string {
+	if acct.destroyed {
+		return ""
+	}
+
 	var b bytes.Buffer
+	defer func() { wipe(b.Bytes()) }()
 	b.Write(seedHeaderV1)
 
 	seedPrefix := []byte{byte(0x00)}
@@ -303,19 +329,21 @@ func (acct *AccountV1) Seed() string {
 
 	b.Write(acct.seedCore[:])
 
-	checksum := sha3.Sum256(b.Bytes())
-	b.Write(checksum[:seedChecksumLength])
-
-	return encoding.ToBase58(b.Bytes())
+	return encoding.ToBase58Check(b.Bytes(), encoding.SHA3Checksum)
 }
 
 func (acct *AccountV1) RecoveryPhrase(lang language.Tag) ([]string, error) {
+	if acct.destroyed {
+		return nil, ErrAccountDestroyed
+	}
+
 	dict, err := getBIP39Dict(lang)
 	if err != nil {
 		return nil, err
 	}
 
 	buf := new(bytes.Buffer)
+	defer func() { wipe(buf.Bytes()) }()
 	switch acct.Network() {
 	case sdk.Livenet:
 		buf.Write([]byte{00})
@@ -327,13 +355,18 @@ func (acct *AccountV1) RecoveryPhrase(lang language.Tag) ([]string, error) {
 }
 
 func (acct *AccountV1) AccountNumber() string {
-	buffer := acct.Bytes()
-	checksum := sha3.Sum256(buffer)
-	buffer = append(buffer, checksum[:ChecksumLength]...)
-	return encoding.ToBase58(buffer)
+	if acct.destroyed {
+		return ""
+	}
+
+	return encoding.ToBase58Check(acct.Bytes(), encoding.SHA3Checksum)
 }
 
 func (acct *AccountV1) Bytes() []byte {
+	if acct.destroyed {
+		return nil
+	}
+
 	keyVariant := byte(acct.AuthKey.Algorithm()<<algorithmShift) | pubkeyMask
 	if acct.network == sdk.Testnet {
 		keyVariant |= testnetMask
@@ -341,19 +374,44 @@ func (acct *AccountV1) Bytes() []byte {
 	return append([]byte{keyVariant}, acct.AuthKey.PublicKeyBytes()...)
 }
 
+// Sign returns nil once the account has been destroyed
 func (acct *AccountV1) Sign(message []byte) []byte {
+	if acct.destroyed {
+		return nil
+	}
 	return acct.AuthKey.Sign(message)
 }
 
+// Destroy zeroes the seed and private keys; the account can no longer sign afterwards
+func (acct *AccountV1) Destroy() {
+	if acct == nil {
+		return
+	}
+	if acct.seedCore != nil {
+		wipe(acct.seedCore[:])
+	}
+	wipeKeys(acct.AuthKey, acct.EncrKey)
+	acct.destroyed = true
+}
+
+func (acct AccountV1) String() string {
+	return formatAccount(&acct, acct.destroyed)
+}
+
+func (acct AccountV1) Format(f fmt.State, verb rune) {
+	io.WriteString(f, acct.String())
+}
+
 func (acct AccountV1) Version() Version {
 	return V1
 }
 
 type AccountV2 struct {
-	network  sdk.Network
-	seedCore []byte
-	AuthKey  AuthKey
-	EncrKey  EncrKey
+	network   sdk.Network
+	seedCore  []byte
+	AuthKey   AuthKey
+	EncrKey   EncrKey
+	destroyed bool
 }
 
 func NewAccountV2(seedCore []byte) (*AccountV2, error) {
@@ -361,6 +419,11 @@ func NewAccountV2(seedCore []byte) (*AccountV2, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		for _, k := range keys {
+			wipe(k)
+		}
+	}()
 
 	authKey, err := NewAuthKey(keys[0])
 	if err != nil {
@@ -374,7 +437,7 @@ func NewAccountV2(seedCore []byte) (*AccountV2, error) {
 
 	return &AccountV2{
 		network:  sdk.GetNetwork(),
-		seedCore: seedCore,
+		seedCore: copyBytes(seedCore),
 		AuthKey:  authKey,
 		EncrKey:  encrKey,
 	}, nil
@@ -422,18 +485,24 @@ func (acct *AccountV2) Network() sdk.Network {
 }
 
 func (acct *AccountV2) Seed() string {
-	b := make([]byte, 0, seedHeaderLength+seedCoreV2Length+seedChecksumLength)
+	if acct.destroyed {
+		return ""
+	}
+
+	b := make([]byte, 0, seedHeaderLength+seedCoreV2Length)
+	defer func() { wipe(b) }()
 
 	b = append(b, seedHeaderV2...)
 	b = append(b, acct.seedCore...)
-	checksum := sha3.Sum256(b)
-	b = append(b, checksum[:seedChecksumLength]...)
-	b58Seed := util.ToBase58(b)
 
-	return b58Seed
+	return encoding.ToBase58Check(b, encoding.SHA3Checksum)
 }
 
 func (acct *AccountV2) RecoveryPhrase(lang language.Tag) ([]string, error) {
+	if acct.destroyed {
+		return nil, ErrAccountDestroyed
+	}
+
 	dict, err := getBIP39Dict(lang)
 	if err != nil {
 		return nil, err
@@ -443,13 +512,18 @@ func (acct *AccountV2) RecoveryPhrase(lang language.Tag) ([]string, error) {
 }
 
 func (acct *AccountV2) AccountNumber() string {
-	buffer := acct.Bytes()
-	checksum := sha3.Sum256(buffer)
-	buffer = append(buffer, checksum[:ChecksumLength]...)
-	return encoding.ToBase58(buffer)
+	if acct.destroyed {
+		return ""
+	}
+
+	return encoding.ToBase58Check(acct.Bytes(), encoding.SHA3Checksum)
 }
 
 func (acct *AccountV2) Bytes() []byte {
+	if acct.destroyed {
+		return nil
+	}
+
 	keyVariant := byte(acct.AuthKey.Algorithm()<<algorithmShift) | pubkeyMask
 	if acct.network == sdk.Testnet {
 		keyVariant |= testnetMask
@@ -457,14 +531,54 @@ func (acct *AccountV2) Bytes() []byte {
 	return append([]byte{keyVariant}, acct.AuthKey.PublicKeyBytes()...)
 }
 
+// Sign returns nil once the account has been destroyed
 func (acct *AccountV2) Sign(message []byte) []byte {
+	if acct.destroyed {
+		return nil
+	}
 	return acct.AuthKey.Sign(message)
 }
 
+// Destroy zeroes the seed and private keys; the account can no longer sign afterwards
+func (acct *AccountV2) Destroy() {
+	if acct == nil {
+		return
+	}
+	wipe(acct.seedCore)
+	wipeKeys(acct.AuthKey, acct.EncrKey)
+	acct.destroyed = true
+}
+
+func wipeKeys(authKey AuthKey, encrKey EncrKey) {
+	if authKey != nil {
+		authKey.Wipe()
+	}
+	if encrKey != nil {
+		encrKey.Wipe()
+	}
+}
+
+func (acct AccountV2) String() string {
+	return formatAccount(&acct, acct.destroyed)
+}
+
+func (acct AccountV2) Format(f fmt.State, verb rune) {
+	io.WriteString(f, acct.String())
+}
+
 func (acct AccountV2) Version() Version {
 	return V2
 }
 
+// formatAccount never includes the seed, so accounts are safe to log
+func formatAccount(acct Account, destroyed bool) string {
+	if destroyed {
+		return fmt.Sprintf("Account%s{destroyed}", strings.ToUpper(string(acct.Version())))
+	}
+	return fmt.Sprintf("Account%s{network: %s, account_number: %s, seed: %s}",
+		strings.ToUpper(string(acct.Version())), acct.Network(), acct.AccountNumber(), redacted)
+}
+
 func ValidateAccountNumber(accountNumber string) (err error) {
 	_, err = extractAuthPublicKey(accountNumber)
 	return
@@ -477,26 +591,24 @@ func Verify(accountNumber string, message, signature []byte) error {
 	}
 
 	if !ed25519.Verify(pubkey, message, signature) {
-		return errors.New("invalid signature")
+		return ErrInvalidSignature
 	}
 
 	return nil
 }
 
 func extractAuthPublicKey(accountNumber string) (publicKey []byte, err error) {
-	accountNumberBytes := encoding.FromBase58(accountNumber)
-	if len(accountNumberBytes) == 0 {
-		return nil, errors.New("invalid base58 string")
+	variantAndPubkey, err := encoding.FromBase58Check(accountNumber, encoding.SHA3Checksum)
+	if err != nil {
+		return nil, err
 	}
 
-	variantAndPubkey := accountNumberBytes[:len(accountNumberBytes)-ChecksumLength]
-	computedChecksum := sha3.Sum256(variantAndPubkey)
-	if !bytes.Equal(computedChecksum[:ChecksumLength], accountNumberBytes[len(accountNumberBytes)-ChecksumLength:]) {
-		return nil, ErrInvalidChecksum
+	if len(variantAndPubkey)+ChecksumLength != Base58AccountNumberLength {
+		return nil, ErrInvalidAccountNumber
 	}
 
 	network := sdk.Livenet
-	if accountNumberBytes[0]&testnetMask > 0 {
+	if variantAndPubkey[0]&testnetMask > 0 {
 		network = sdk.Testnet
 	}
 
@@ -5,6 +5,8 @@
 package account
 
 import (
+	"encoding/hex"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -329,3 +331,100 @@ func TestVerify(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestDestroyedAccountRefusesToSign(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	seeds := []string{testnetAccounts[0].seed, testnetDeprecatedAccount.seed}
+	for _, seed := range seeds {
+		acct, err := FromSeed(seed)
+		assert.NoError(t, err)
+
+		accountNumber := acct.AccountNumber()
+		msg := []byte("Hello, world!")
+		assert.NoError(t, Verify(accountNumber, msg, acct.Sign(msg)))
+
+		acct.Destroy()
+
+		assert.Nil(t, acct.Sign(msg))
+		assert.Error(t, Verify(accountNumber, msg, acct.Sign(msg)))
+		assert.Empty(t, acct.Seed())
+		assert.Empty(t, acct.AccountNumber())
+
+		_, err = acct.RecoveryPhrase(language.AmericanEnglish)
+		assert.EqualError(t, err, ErrAccountDestroyed.Error())
+	}
+}
+
+func TestDestroyKeepsCallerSeed(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	var seedCore [seedCoreV1Length]byte
+	for i := range seedCore {
+		seedCore[i] = byte(i + 1)
+	}
+	expected := seedCore
+
+	acct, err := NewAccountV1(&seedCore)
+	assert.NoError(t, err)
+	acct.Destroy()
+	assert.Equal(t, expected, seedCore)
+
+	_, err = NewAccountV1(nil)
+	assert.EqualError(t, err, ErrInvalidSeed.Error())
+
+	assert.NotPanics(t, func() {
+		(*AccountV1)(nil).Destroy()
+		(&AccountV1{}).Destroy()
+		(*AccountV2)(nil).Destroy()
+		(&AccountV2{}).Destroy()
+	})
+}
+
+func TestWipedKeys(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	acct, err := FromSeed(testnetAccounts[0].seed)
+	assert.NoError(t, err)
+	v2 := acct.(*AccountV2)
+
+	// accessors return copies, so mutating them does not affect the key
+	priv := v2.AuthKey.PrivateKeyBytes()
+	wipe(priv)
+	assert.NotNil(t, v2.AuthKey.Sign([]byte("msg")))
+
+	v2.AuthKey.Wipe()
+	v2.EncrKey.Wipe()
+	assert.True(t, isZero(v2.AuthKey.PrivateKeyBytes()))
+	assert.True(t, isZero(v2.EncrKey.PrivateKeyBytes()))
+	assert.Nil(t, v2.AuthKey.Sign([]byte("msg")))
+
+	_, err = v2.EncrKey.Encrypt([]byte("msg"), v2.EncrKey.PublicKeyBytes())
+	assert.EqualError(t, err, ErrKeyWiped.Error())
+}
+
+func TestRedactedFormat(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	for _, data := range []valid{testnetAccounts[0], testnetDeprecatedAccount} {
+		acct, err := FromSeed(data.seed)
+		assert.NoError(t, err)
+
+		for _, verb := range []string{"%s", "%v", "%+v", "%#v"} {
+			out := fmt.Sprintf(verb, acct)
+			assert.NotContains(t, out, data.seed)
+			assert.Contains(t, out, data.accountNumber)
+			assert.Contains(t, out, redacted)
+		}
+
+		var authKey AuthKey
+		switch a := acct.(type) {
+		case *AccountV1:
+			authKey = a.AuthKey
+		case *AccountV2:
+			authKey = a.AuthKey
+		}
+		out := fmt.Sprintf("%#v", authKey)
+		assert.NotContains(t, out, hex.EncodeToString(authKey.PrivateKeyBytes()[:32]))
+	}
+}
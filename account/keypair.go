@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"errors"
+	"fmt"
 	"io"
 
 	"golang.org/x/crypto/ed25519"
@@ -23,6 +24,7 @@ type AsymmetricKey interface {
 	PrivateKeyBytes() []byte
 	PublicKeyBytes() []byte
 	Algorithm() int
+	Wipe()
 }
 
 type AuthKey interface {
@@ -35,20 +37,39 @@ type ED25519AuthKey struct {
 	privateKey ed25519.PrivateKey
 }
 
+// PrivateKeyBytes returns a copy of the private key
 func (e ED25519AuthKey) PrivateKeyBytes() []byte {
-	return e.privateKey
+	return copyBytes(e.privateKey)
 }
 
+// PublicKeyBytes returns a copy of the public key
 func (e ED25519AuthKey) PublicKeyBytes() []byte {
-	return e.privateKey[ed25519.PrivateKeySize-ed25519.PublicKeySize:]
+	return copyBytes(e.privateKey[ed25519.PrivateKeySize-ed25519.PublicKeySize:])
 }
 
 func (e ED25519AuthKey) Algorithm() int {
 	return AlgEd25519
 }
 
+// Sign returns nil once the key has been wiped
 func (e ED25519AuthKey) Sign(message []byte) []byte {
-	return ed25519.Sign(e.PrivateKeyBytes(), message)
+	if len(e.privateKey) != ed25519.PrivateKeySize || isZero(e.privateKey) {
+		return nil
+	}
+	return ed25519.Sign(e.privateKey, message)
+}
+
+// Wipe zeroes the private key in place
+func (e ED25519AuthKey) Wipe() {
+	wipe(e.privateKey)
+}
+
+func (e ED25519AuthKey) String() string {
+	return fmt.Sprintf("ED25519AuthKey{public: %x, private: %s}", e.PublicKeyBytes(), redacted)
+}
+
+func (e ED25519AuthKey) Format(f fmt.State, verb rune) {
+	io.WriteString(f, e.String())
 }
 
 func NewAuthKey(entropy []byte) (AuthKey, error) {
@@ -69,19 +90,38 @@ type NaclBoxEncrKey struct {
 	privateKey *[32]byte
 }
 
+// PrivateKeyBytes returns a copy of the private key
 func (n NaclBoxEncrKey) PrivateKeyBytes() []byte {
-	return n.privateKey[:]
+	return copyBytes(n.privateKey[:])
 }
 
+// PublicKeyBytes returns a copy of the public key
 func (n NaclBoxEncrKey) PublicKeyBytes() []byte {
-	return n.publicKey[:]
+	return copyBytes(n.publicKey[:])
 }
 
 func (n NaclBoxEncrKey) Algorithm() int {
 	return AlgNaclBox
 }
 
+// Wipe zeroes the private key in place
+func (n NaclBoxEncrKey) Wipe() {
+	wipe(n.privateKey[:])
+}
+
+func (n NaclBoxEncrKey) String() string {
+	return fmt.Sprintf("NaclBoxEncrKey{public: %x, private: %s}", n.publicKey[:], redacted)
+}
+
+func (n NaclBoxEncrKey) Format(f fmt.State, verb rune) {
+	io.WriteString(f, n.String())
+}
+
 func (n NaclBoxEncrKey) Encrypt(plaintext []byte, peerPublicKey []byte) ([]byte, error) {
+	if isZero(n.privateKey[:]) {
+		return nil, ErrKeyWiped
+	}
+
 	var nonce [24]byte
 	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
 		return nil, err
@@ -95,6 +135,10 @@ func (n NaclBoxEncrKey) Encrypt(plaintext []byte, peerPublicKey []byte) ([]byte,
 }
 
 func (n NaclBoxEncrKey) Decrypt(ciphertext []byte, peerPublicKey []byte) ([]byte, error) {
+	if isZero(n.privateKey[:]) {
+		return nil, ErrKeyWiped
+	}
+
 	var nonce [24]byte
 	copy(nonce[:], ciphertext[:24])
 
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package account
+
+import "runtime"
+
+const redacted = "[REDACTED]"
+
+// wipe overwrites the secret in place so it does not linger in memory
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	// keep the writes from being optimised away
+	runtime.KeepAlive(b)
+}
+
+func isZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
+
+func copyBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+const (
+	// DefaultBatchSize is the number of registrations sent per request
+	DefaultBatchSize = 100
+	// MaxBatchSize is the most registrations the API accepts in one request
+	MaxBatchSize = 100
+)
+
+var (
+	ErrInvalidBatchSize       = errors.New("invalid batch size: max = 100")
+	ErrUnexpectedResponse     = errors.New("unexpected number of assets in the response")
+	ErrNullRegistrationParams = errors.New("registration params are null")
+	ErrMissingFromResponse    = errors.New("asset missing from the response")
+)
+
+type BatchOptions struct {
+	// BatchSize is the number of registrations per request, DefaultBatchSize if 0
+	BatchSize int
+	// Workers is the number of requests in flight, 1 if 0
+	Workers int
+	// Registrant signs the registrations before they are sent, if set
+	Registrant account.Account
+}
+
+// RegistrationResult is the outcome of one registration of a batch
+type RegistrationResult struct {
+	ID        string
+	Duplicate bool // already registered, or repeated within the batch
+	Err       error
+}
+
+// RegisterBatch registers many assets, chunked into requests of opts.BatchSize.
+// The results are in the order of params. A registration which fails to sign or
+// verify is not sent, and a failed request fails all the registrations it carried;
+// neither stops the other batches. The returned error is only set if ctx is done.
+func RegisterBatch(ctx context.Context, params []*RegistrationParams, opts *BatchOptions) ([]RegistrationResult, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+	if batchSize < 0 || batchSize > MaxBatchSize {
+		return nil, ErrInvalidBatchSize
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	results := make([]RegistrationResult, len(params))
+
+	// each asset is sent once, repeats take the result of the first
+	first := make(map[string]int)
+	repeats := make(map[int]int)
+	pending := make([]int, 0, len(params))
+	for i, p := range params {
+		if p == nil {
+			results[i].Err = ErrNullRegistrationParams
+			continue
+		}
+		if opts.Registrant != nil {
+			if err := p.Sign(opts.Registrant); err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		if err := p.Verify(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		assetID := p.AssetID()
+		if j, ok := first[assetID]; ok {
+			repeats[i] = j
+			continue
+		}
+		first[assetID] = i
+		pending = append(pending, i)
+	}
+
+	batches := make(chan []int)
+	go func() {
+		defer close(batches)
+		for start := 0; start < len(pending); start += batchSize {
+			end := start + batchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			select {
+			case batches <- pending[start:end]:
+			case <-ctx.Done():
+				for _, i := range pending[start:] {
+					results[i].Err = ctx.Err()
+				}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				registerBatch(ctx, params, batch, results)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, j := range repeats {
+		results[i] = results[j]
+		results[i].Duplicate = true
+	}
+
+	return results, ctx.Err()
+}
+
+func registerBatch(ctx context.Context, params []*RegistrationParams, batch []int, results []RegistrationResult) {
+	assets := make([]*RegistrationParams, len(batch))
+	for k, i := range batch {
+		assets[k] = params[i]
+	}
+
+	items, err := register(ctx, assets)
+	if err != nil {
+		for _, i := range batch {
+			results[i] = RegistrationResult{ID: params[i].AssetID(), Err: err}
+		}
+		return
+	}
+
+	// the response items are matched by asset ID, not by position
+	registered := make(map[string]registeredItem, len(items))
+	for _, item := range items {
+		registered[item.ID] = item
+	}
+	for _, i := range batch {
+		assetID := params[i].AssetID()
+		item, ok := registered[assetID]
+		if !ok {
+			results[i] = RegistrationResult{ID: assetID, Err: ErrMissingFromResponse}
+			continue
+		}
+		results[i] = RegistrationResult{ID: item.ID, Duplicate: item.Duplicate}
+	}
+}
+
+func register(ctx context.Context, assets []*RegistrationParams) ([]registeredItem, error) {
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(registrationRequest{Assets: assets}); err != nil {
+		return nil, err
+	}
+
+	client := sdk.GetAPIClient()
+	req, err := client.NewRequest("POST", "/v3/register-asset", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Assets []registeredItem `json:"assets"`
+	}
+	if err := client.Do(req.WithContext(ctx), &result); err != nil {
+		return nil, err
+	}
+	return result.Assets, nil
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+)
+
+func newBatchServer(t *testing.T, handler func(assets []*RegistrationParams) (int, interface{})) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req registrationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		status, resp := handler(req.Assets)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}))
+
+	sdk.Init(&sdk.Config{
+		HTTPClient: ts.Client(),
+		Network:    sdk.Testnet,
+	})
+	sdk.GetAPIClient().URLAuthority = ts.URL
+	return ts
+}
+
+func newBatchParams(t *testing.T, n int) []*RegistrationParams {
+	params := make([]*RegistrationParams, n)
+	for i := range params {
+		p, err := NewRegistrationParams(fmt.Sprintf("asset %d", i), nil)
+		assert.NoError(t, err)
+		assert.NoError(t, p.SetFingerprintFromData([]byte(fmt.Sprintf("content %d", i))))
+		params[i] = p
+	}
+	return params
+}
+
+func registeredResponse(assets []*RegistrationParams) (int, interface{}) {
+	items := make([]registeredItem, len(assets))
+	for i, a := range assets {
+		items[i] = registeredItem{ID: a.AssetID(), Duplicate: strings.HasSuffix(a.Name, "7")}
+	}
+	return http.StatusOK, map[string]interface{}{"assets": items}
+}
+
+func TestRegisterBatch(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		sizes    []int
+		inFlight int32
+		maxIn    int32
+	)
+	ts := newBatchServer(t, func(assets []*RegistrationParams) (int, interface{}) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		lock.Lock()
+		sizes = append(sizes, len(assets))
+		if n > maxIn {
+			maxIn = n
+		}
+		lock.Unlock()
+		return registeredResponse(assets)
+	})
+	defer ts.Close()
+
+	params := newBatchParams(t, 25)
+	results, err := RegisterBatch(context.Background(), params, &BatchOptions{
+		BatchSize:  10,
+		Workers:    2,
+		Registrant: registrant,
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{10, 10, 5}, sizes)
+	assert.LessOrEqual(t, maxIn, int32(2))
+
+	for i, r := range results {
+		assert.NoError(t, r.Err)
+		assert.Equal(t, params[i].AssetID(), r.ID)
+		assert.Equal(t, i%10 == 7, r.Duplicate)
+	}
+}
+
+func TestRegisterBatchItemErrors(t *testing.T) {
+	var requests int32
+	ts := newBatchServer(t, func(assets []*RegistrationParams) (int, interface{}) {
+		atomic.AddInt32(&requests, 1)
+		return registeredResponse(assets)
+	})
+	defer ts.Close()
+
+	params := newBatchParams(t, 3)
+	for _, p := range params {
+		assert.NoError(t, p.Sign(registrant))
+	}
+	params[1].Signature = params[0].Signature
+
+	repeat := *params[0]
+	params = append(params, &repeat, nil)
+
+	results, err := RegisterBatch(context.Background(), params, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	assert.Equal(t, RegistrationResult{ID: params[0].AssetID()}, results[0])
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, RegistrationResult{ID: params[2].AssetID()}, results[2])
+	assert.Equal(t, RegistrationResult{ID: params[0].AssetID(), Duplicate: true}, results[3])
+	assert.Equal(t, ErrNullRegistrationParams, results[4].Err)
+}
+
+func TestRegisterBatchRequestError(t *testing.T) {
+	ts := newBatchServer(t, func(assets []*RegistrationParams) (int, interface{}) {
+		if assets[0].Name == "asset 0" {
+			return http.StatusBadRequest, sdk.APIError{Code: 1000, Message: "invalid parameters"}
+		}
+		return registeredResponse(assets)
+	})
+	defer ts.Close()
+
+	params := newBatchParams(t, 4)
+	results, err := RegisterBatch(context.Background(), params, &BatchOptions{BatchSize: 2, Registrant: registrant})
+	assert.NoError(t, err)
+
+	for i, r := range results {
+		assert.Equal(t, params[i].AssetID(), r.ID)
+		if i < 2 {
+			assert.IsType(t, &sdk.APIError{}, r.Err)
+		} else {
+			assert.NoError(t, r.Err)
+		}
+	}
+}
+
+func TestRegisterBatchResponseOrder(t *testing.T) {
+	ts := newBatchServer(t, func(assets []*RegistrationParams) (int, interface{}) {
+		// reversed, and without the first asset
+		items := make([]registeredItem, 0)
+		for i := len(assets) - 1; i > 0; i-- {
+			items = append(items, registeredItem{ID: assets[i].AssetID(), Duplicate: i == 1})
+		}
+		return http.StatusOK, map[string]interface{}{"assets": items}
+	})
+	defer ts.Close()
+
+	params := newBatchParams(t, 3)
+	results, err := RegisterBatch(context.Background(), params, &BatchOptions{Registrant: registrant})
+	assert.NoError(t, err)
+
+	assert.Equal(t, RegistrationResult{ID: params[0].AssetID(), Err: ErrMissingFromResponse}, results[0])
+	assert.Equal(t, RegistrationResult{ID: params[1].AssetID(), Duplicate: true}, results[1])
+	assert.Equal(t, RegistrationResult{ID: params[2].AssetID()}, results[2])
+}
+
+func TestRegisterBatchCanceled(t *testing.T) {
+	ts := newBatchServer(t, registeredResponse)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := RegisterBatch(ctx, newBatchParams(t, 3), &BatchOptions{Registrant: registrant})
+	assert.Equal(t, context.Canceled, err)
+	for _, r := range results {
+		assert.Error(t, r.Err)
+	}
+}
+
+func TestRegisterBatchInvalidSize(t *testing.T) {
+	_, err := RegisterBatch(context.Background(), nil, &BatchOptions{BatchSize: 101})
+	assert.Equal(t, ErrInvalidBatchSize, err)
+}
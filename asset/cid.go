@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+const (
+	cidVersion1     = 0x01
+	codecRaw        = 0x55
+	codecDagPB      = 0x70
+	multihashSHA256 = 0x12
+	multibaseBase32 = "b"
+
+	// CIDChunkSize is the IPFS default chunk size, content is split into raw
+	// leaves of this size
+	CIDChunkSize = 256 * 1024
+
+	// cidMaxLinks is the most children of a node in the IPFS balanced layout
+	cidMaxLinks = 174
+	// unixfsFile is the UnixFS data type of a file node
+	unixfsFile = 2
+)
+
+var cidEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ComputeCID returns the CIDv1 IPFS gives the content when it is added with the
+// default chunker, balanced layout, raw leaves and CID version 1, in base32.
+// Content which fits in one chunk is a single raw block, anything larger is a
+// UnixFS DAG of dag-pb nodes over raw leaves.
+func ComputeCID(reader io.Reader) (string, error) {
+	h := newCIDHasher()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return h.CID(), nil
+}
+
+// CIDFromData is ComputeCID for data in memory
+func CIDFromData(content []byte) string {
+	cid, _ := ComputeCID(bytes.NewReader(content))
+	return cid
+}
+
+// cidNode is a block of the DAG as linked from its parent
+type cidNode struct {
+	cid   []byte // binary CID
+	size  uint64 // bytes of content under the node
+	tsize uint64 // bytes of the node and all the blocks under it
+}
+
+// cidHasher chunks what is written to it into raw leaves, only the leaf CIDs
+// are kept so content of any size can be hashed
+type cidHasher struct {
+	chunk  []byte
+	leaves []cidNode
+}
+
+func newCIDHasher() *cidHasher {
+	return &cidHasher{chunk: make([]byte, 0, CIDChunkSize)}
+}
+
+func (h *cidHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		free := CIDChunkSize - len(h.chunk)
+		if free > len(p) {
+			free = len(p)
+		}
+		h.chunk = append(h.chunk, p[:free]...)
+		p = p[free:]
+		if len(h.chunk) == CIDChunkSize {
+			h.flush()
+		}
+	}
+	return n, nil
+}
+
+func (h *cidHasher) flush() {
+	digest := sha256.Sum256(h.chunk)
+	h.leaves = append(h.leaves, cidNode{
+		cid:   binaryCID(codecRaw, digest[:]),
+		size:  uint64(len(h.chunk)),
+		tsize: uint64(len(h.chunk)),
+	})
+	h.chunk = h.chunk[:0]
+}
+
+// CID returns the CID of the content written so far
+func (h *cidHasher) CID() string {
+	leaves := h.leaves
+	if len(h.chunk) > 0 || len(leaves) == 0 {
+		digest := sha256.Sum256(h.chunk)
+		size := uint64(len(h.chunk))
+		leaves = append(leaves[:len(leaves):len(leaves)], cidNode{
+			cid:   binaryCID(codecRaw, digest[:]),
+			size:  size,
+			tsize: size,
+		})
+	}
+	if len(leaves) == 1 {
+		return encodeCID(leaves[0].cid)
+	}
+
+	// the root spans the fewest levels that hold all the leaves
+	span := 1
+	for span*cidMaxLinks < len(leaves) {
+		span *= cidMaxLinks
+	}
+	return encodeCID(balancedNode(leaves, span).cid)
+}
+
+// balancedNode links the leaves in children of span leaves each, filled from
+// the left; a child is a leaf when span is 1
+func balancedNode(leaves []cidNode, span int) cidNode {
+	children := make([]cidNode, 0, cidMaxLinks)
+	for start := 0; start < len(leaves); start += span {
+		end := start + span
+		if end > len(leaves) {
+			end = len(leaves)
+		}
+		if span == 1 {
+			children = append(children, leaves[start])
+		} else {
+			children = append(children, balancedNode(leaves[start:end], span/cidMaxLinks))
+		}
+	}
+	return fileNode(children)
+}
+
+// fileNode encodes a dag-pb node holding UnixFS file data over the children
+func fileNode(children []cidNode) cidNode {
+	var size uint64
+	for _, child := range children {
+		size += child.size
+	}
+
+	// UnixFS Data: Type, filesize, blocksizes
+	data := []byte{0x08, unixfsFile, 0x18}
+	data = appendUvarint(data, size)
+	for _, child := range children {
+		data = append(data, 0x20)
+		data = appendUvarint(data, child.size)
+	}
+
+	// PBNode: Links, then Data; a PBLink is Hash, an empty Name and Tsize
+	var node []byte
+	tsize := uint64(0)
+	for _, child := range children {
+		link := []byte{0x0a}
+		link = appendUvarint(link, uint64(len(child.cid)))
+		link = append(link, child.cid...)
+		link = append(link, 0x12, 0x00, 0x18)
+		link = appendUvarint(link, child.tsize)
+
+		node = append(node, 0x12)
+		node = appendUvarint(node, uint64(len(link)))
+		node = append(node, link...)
+		tsize += child.tsize
+	}
+	node = append(node, 0x0a)
+	node = appendUvarint(node, uint64(len(data)))
+	node = append(node, data...)
+
+	digest := sha256.Sum256(node)
+	return cidNode{
+		cid:   binaryCID(codecDagPB, digest[:]),
+		size:  size,
+		tsize: tsize + uint64(len(node)),
+	}
+}
+
+func binaryCID(codec byte, digest []byte) []byte {
+	b := make([]byte, 0, 4+len(digest))
+	b = append(b, cidVersion1, codec, multihashSHA256, byte(len(digest)))
+	return append(b, digest...)
+}
+
+func encodeCID(cid []byte) string {
+	return multibaseBase32 + strings.ToLower(cidEncoding.EncodeToString(cid))
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
@@ -5,16 +5,15 @@
 package asset
 
 import (
-	"bytes"
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	sdk "github.com/bitmark-inc/bitmark-sdk-go"
 	"github.com/bitmark-inc/bitmark-sdk-go/utils"
-	"golang.org/x/crypto/sha3"
 )
 
 type registrationRequest struct {
@@ -27,24 +26,14 @@ type registeredItem struct {
 }
 
 func Register(params *RegistrationParams) (string, error) {
-	r := registrationRequest{
-		Assets: []*RegistrationParams{params},
-	}
-	body := new(bytes.Buffer)
-	json.NewEncoder(body).Encode(r)
-
-	client := sdk.GetAPIClient()
-	req, _ := client.NewRequest("POST", "/v3/register-asset", body)
-
-	var result struct {
-		Assets []registeredItem `json:"assets"`
+	items, err := register(context.Background(), []*RegistrationParams{params})
+	if err != nil {
+		return params.AssetID(), err
 	}
-	if err := client.Do(req, &result); err != nil {
-		digest := sha3.Sum512([]byte(params.Fingerprint))
-		assetID := hex.EncodeToString(digest[:])
-		return assetID, err
+	if len(items) != 1 {
+		return params.AssetID(), ErrUnexpectedResponse
 	}
-	return result.Assets[0].ID, nil
+	return items[0].ID, nil
 }
 
 func Get(assetID string) (*Asset, error) {
@@ -90,6 +79,15 @@ func List(builder *QueryParamsBuilder) ([]*Asset, error) {
 	return result.Assets, nil
 }
 
+var (
+	ErrInvalidLimit          = errors.New("invalid size: max = 100")
+	ErrInvalidDirection      = errors.New("it must be 'later' or 'earlier'")
+	ErrEmptyNameFilter       = errors.New("name filter is empty")
+	ErrInvalidMetadataFilter = errors.New("metadata filter key must be non-empty and without ':'")
+	ErrInvalidBlockRange     = errors.New("invalid block range")
+	ErrInvalidTimeRange      = errors.New("invalid created time range")
+)
+
 type QueryParamsBuilder struct {
 	params url.Values
 	err    error
@@ -111,6 +109,67 @@ func (qb *QueryParamsBuilder) AssetIDs(assetIDs []string) *QueryParamsBuilder {
 	return qb
 }
 
+// NameContains matches assets with the substring in their name
+func (qb *QueryParamsBuilder) NameContains(substring string) *QueryParamsBuilder {
+	if substring == "" {
+		qb.err = ErrEmptyNameFilter
+	}
+	qb.params.Set("name", substring)
+	return qb
+}
+
+// Metadata matches assets with the key set to value in their metadata,
+// all the pairs given must match
+func (qb *QueryParamsBuilder) Metadata(key, value string) *QueryParamsBuilder {
+	if key == "" || strings.Contains(key, ":") {
+		qb.err = ErrInvalidMetadataFilter
+	}
+	qb.params.Add("metadata", key+":"+value)
+	return qb
+}
+
+func (qb *QueryParamsBuilder) Fingerprint(fingerprint string) *QueryParamsBuilder {
+	kind := -1
+	if len(fingerprint) > 2 {
+		kind, _ = strconv.Atoi(fingerprint[:2])
+	}
+	if _, ok := fingerprintTypeNames[kind]; !ok {
+		qb.err = ErrUnknownFingerprintType
+	}
+	qb.params.Set("fingerprint", fingerprint)
+	return qb
+}
+
+// BlockRange matches assets registered in blocks from to to inclusive,
+// a bound of 0 is open
+func (qb *QueryParamsBuilder) BlockRange(from, to int) *QueryParamsBuilder {
+	if from < 0 || to < 0 || (to != 0 && from > to) {
+		qb.err = ErrInvalidBlockRange
+	}
+	if from > 0 {
+		qb.params.Set("block_number_from", strconv.Itoa(from))
+	}
+	if to > 0 {
+		qb.params.Set("block_number_to", strconv.Itoa(to))
+	}
+	return qb
+}
+
+// CreatedBetween matches assets created from after to before inclusive,
+// a zero time bound is open
+func (qb *QueryParamsBuilder) CreatedBetween(after, before time.Time) *QueryParamsBuilder {
+	if !after.IsZero() && !before.IsZero() && after.After(before) {
+		qb.err = ErrInvalidTimeRange
+	}
+	if !after.IsZero() {
+		qb.params.Set("created_after", after.UTC().Format(time.RFC3339))
+	}
+	if !before.IsZero() {
+		qb.params.Set("created_before", before.UTC().Format(time.RFC3339))
+	}
+	return qb
+}
+
 func (qb *QueryParamsBuilder) Pending(pending bool) *QueryParamsBuilder {
 	qb.params.Set("pending", strconv.FormatBool(pending))
 	return qb
@@ -118,7 +177,7 @@ func (qb *QueryParamsBuilder) Pending(pending bool) *QueryParamsBuilder {
 
 func (qb *QueryParamsBuilder) Limit(size int) *QueryParamsBuilder {
 	if size > 100 {
-		qb.err = errors.New("invalid size: max = 100")
+		qb.err = ErrInvalidLimit
 	}
 	qb.params.Set("limit", strconv.Itoa(size))
 	return qb
@@ -130,6 +189,9 @@ func (qb *QueryParamsBuilder) At(at int) *QueryParamsBuilder {
 }
 
 func (qb *QueryParamsBuilder) To(direction utils.Direction) *QueryParamsBuilder {
+	if direction != "" && (direction != utils.Later && direction != utils.Earlier) {
+		qb.err = ErrInvalidDirection
+	}
 	qb.params.Set("to", string(direction))
 	return qb
 }
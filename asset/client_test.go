@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -166,3 +167,57 @@ func TestListAsset(t *testing.T) {
 	assert.Equal(t, len(assets), 2)
 	assert.NoError(t, err)
 }
+
+func TestQueryParamsBuilderFilters(t *testing.T) {
+	after := time.Date(2020, 1, 1, 7, 0, 0, 0, time.FixedZone("ICT", 7*3600))
+	before := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	params, err := NewQueryParamsBuilder().
+		NameContains("logo").
+		Metadata("source", "camera").
+		Metadata("creator", "Alice: the artist").
+		Fingerprint("01abcd").
+		BlockRange(100, 200).
+		CreatedBetween(after, before).
+		Build()
+	assert.NoError(t, err)
+
+	query, _ := url.ParseQuery(params)
+	assert.Equal(t, url.Values{
+		"name":              {"logo"},
+		"metadata":          {"source:camera", "creator:Alice: the artist"},
+		"fingerprint":       {"01abcd"},
+		"block_number_from": {"100"},
+		"block_number_to":   {"200"},
+		"created_after":     {"2020-01-01T00:00:00Z"},
+		"created_before":    {"2020-02-01T00:00:00Z"},
+		"pending":           {"true"},
+	}, query)
+
+	// open bounds
+	params, err = NewQueryParamsBuilder().BlockRange(100, 0).CreatedBetween(time.Time{}, before).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "block_number_from=100&created_before=2020-02-01T00%3A00%3A00Z&pending=true", params)
+}
+
+func TestQueryParamsBuilderErrors(t *testing.T) {
+	testcases := []struct {
+		builder *QueryParamsBuilder
+		err     error
+	}{
+		{NewQueryParamsBuilder().NameContains(""), ErrEmptyNameFilter},
+		{NewQueryParamsBuilder().Metadata("a:b", "c"), ErrInvalidMetadataFilter},
+		{NewQueryParamsBuilder().Metadata("", "c"), ErrInvalidMetadataFilter},
+		{NewQueryParamsBuilder().Fingerprint("99abc"), ErrUnknownFingerprintType},
+		{NewQueryParamsBuilder().BlockRange(200, 100), ErrInvalidBlockRange},
+		{NewQueryParamsBuilder().To(utils.Later).BlockRange(-1, 0), ErrInvalidBlockRange},
+		{NewQueryParamsBuilder().CreatedBetween(time.Unix(10, 0), time.Unix(0, 0)), ErrInvalidTimeRange},
+		{NewQueryParamsBuilder().Limit(101), ErrInvalidLimit},
+		{NewQueryParamsBuilder().To(utils.Direction("sideways")), ErrInvalidDirection},
+	}
+
+	for _, testcase := range testcases {
+		_, err := testcase.builder.Build()
+		assert.Equal(t, testcase.err, err)
+	}
+}
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// DefaultChunkSize is the chunk size of chunked fingerprints when none is given
+	DefaultChunkSize = 4 * 1024 * 1024
+
+	// size of the reads when streaming content into a single digest
+	streamBlockSize = 64 * 1024
+)
+
+var ErrInvalidChunkSize = errors.New("chunk size must be greater than 0")
+
+// FingerprintOptions control how content is fingerprinted. The zero value
+// streams the content into a single SHA3-512 digest, the same fingerprint as
+// SetFingerprintFromData.
+type FingerprintOptions struct {
+	// Chunked splits the content into chunks which are hashed concurrently and
+	// combined with a merkle tree, in content order, into a chunked fingerprint
+	Chunked bool
+	// ChunkSize is the size of each chunk, DefaultChunkSize if 0
+	ChunkSize int
+	// Workers is the number of chunks hashed at once, runtime.NumCPU() if 0.
+	// At most Workers chunks are held in memory.
+	Workers int
+	// Progress is called with the number of bytes hashed so far and the total,
+	// or -1 if the total is unknown. It is never called concurrently.
+	Progress func(hashed, total int64)
+}
+
+func (r *RegistrationParams) SetFingerprintFromFile(name string) error {
+	return r.SetFingerprintFromFileContext(context.Background(), name, nil)
+}
+
+// SetFingerprintFromFileContext fingerprints a file without reading it into memory,
+// it stops with the context error if ctx is cancelled
+func (r *RegistrationParams) SetFingerprintFromFileContext(ctx context.Context, name string, opts *FingerprintOptions) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return ErrEmptyContent
+	}
+
+	return r.setFingerprintFromStream(ctx, f, info.Size(), opts)
+}
+
+// SetFingerprintFromReaderContext is SetFingerprintFromFileContext for content of unknown size
+func (r *RegistrationParams) SetFingerprintFromReaderContext(ctx context.Context, reader io.Reader, opts *FingerprintOptions) error {
+	return r.setFingerprintFromStream(ctx, reader, -1, opts)
+}
+
+func (r *RegistrationParams) setFingerprintFromStream(ctx context.Context, reader io.Reader, total int64, opts *FingerprintOptions) error {
+	if opts == nil {
+		opts = &FingerprintOptions{}
+	}
+
+	if !opts.Chunked {
+		digest, err := hashStream(ctx, reader, total, opts.Progress)
+		if err != nil {
+			return err
+		}
+		r.Fingerprint = fmt.Sprintf("%02d%s", fingerprintTypeSHA3512, hex.EncodeToString(digest))
+		return nil
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < 0 {
+		return ErrInvalidChunkSize
+	}
+
+	hashes, err := hashChunks(ctx, reader, total, chunkSize, opts.Workers, opts.Progress)
+	if err != nil {
+		return err
+	}
+	r.Fingerprint = chunkedFingerprint(chunkSize, hashes)
+	return nil
+}
+
+// chunkedFingerprint is the type, the chunk size and the merkle root of the chunk hashes
+func chunkedFingerprint(chunkSize int, hashes [][]byte) string {
+	tree := buildMerkleTree(hashes, combineHashes)
+
+	root := tree[len(tree)-1]
+	return fmt.Sprintf("%02d%d:%s", fingerprintTypeChunkedMerkleTree, chunkSize, base64.StdEncoding.EncodeToString(root))
+}
+
+func hashStream(ctx context.Context, reader io.Reader, total int64, progress func(int64, int64)) ([]byte, error) {
+	h := sha3.New512()
+	buf := make([]byte, streamBlockSize)
+	hashed := int64(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := reader.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			hashed += int64(n)
+			if progress != nil {
+				progress(hashed, total)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hashed == 0 {
+		return nil, ErrEmptyContent
+	}
+	return h.Sum(nil), nil
+}
+
+type chunk struct {
+	index int
+	data  []byte
+}
+
+type chunkHash struct {
+	index int
+	hash  []byte
+	size  int
+	buf   []byte
+}
+
+// hashChunks reads the content chunk by chunk and hashes up to workers chunks concurrently;
+// the buffers are recycled so memory use is bounded by workers * chunkSize
+func hashChunks(ctx context.Context, reader io.Reader, total int64, chunkSize, workers int, progress func(int64, int64)) ([][]byte, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// buffers are allocated on first use so small content only needs one
+	buffers := make(chan []byte, workers)
+	for i := 0; i < workers; i++ {
+		buffers <- nil
+	}
+
+	jobs := make(chan chunk)
+	results := make(chan chunkHash)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			var buf []byte
+			select {
+			case buf = <-buffers:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			if buf == nil {
+				buf = make([]byte, chunkSize)
+			}
+
+			n, err := io.ReadFull(reader, buf)
+			if n > 0 {
+				select {
+				case jobs <- chunk{index: i, data: buf[:n]}:
+				case <-ctx.Done():
+					readErr <- ctx.Err()
+					return
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				digest := sha3.Sum512(c.data)
+				results <- chunkHash{index: c.index, hash: digest[:], size: len(c.data), buf: c.data[:cap(c.data)]}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	hashes := make([][]byte, 0)
+	hashed := int64(0)
+	for res := range results {
+		for len(hashes) <= res.index {
+			hashes = append(hashes, nil)
+		}
+		hashes[res.index] = res.hash
+		buffers <- res.buf
+
+		hashed += int64(res.size)
+		if progress != nil {
+			progress(hashed, total)
+		}
+	}
+
+	if err := <-readErr; err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, ErrEmptyContent
+	}
+	return hashes, nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestSetFingerprintFromFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "fingerprint")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("hello world")
+	f.Close()
+
+	params, _ := NewRegistrationParams("", nil)
+	assert.NoError(t, params.SetFingerprintFromFile(f.Name()))
+	assert.Equal(t, "01840006653e9ac9e95117a15c915caab81662918e925de9e004f774ff82d7079a40d4d27b1b372657c61d46d470304c88c788b3a4527ad074d1dccbee5dbaa99a", params.Fingerprint)
+
+	var hashed, total int64
+	opts := &FingerprintOptions{Progress: func(h, t int64) { hashed, total = h, t }}
+	assert.NoError(t, params.SetFingerprintFromFileContext(context.Background(), f.Name(), opts))
+	assert.Equal(t, int64(11), hashed)
+	assert.Equal(t, int64(11), total)
+
+	empty, err := ioutil.TempFile("", "fingerprint")
+	assert.NoError(t, err)
+	defer os.Remove(empty.Name())
+	empty.Close()
+	assert.EqualError(t, params.SetFingerprintFromFile(empty.Name()), ErrEmptyContent.Error())
+}
+
+func TestChunkedFingerprint(t *testing.T) {
+	content := make([]byte, 10*1024+7)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	// the root over the chunk hashes, in content order
+	var hashes [][]byte
+	for i := 0; i < len(content); i += 1024 {
+		end := i + 1024
+		if end > len(content) {
+			end = len(content)
+		}
+		digest := sha3.Sum512(content[i:end])
+		hashes = append(hashes, digest[:])
+	}
+	tree := buildMerkleTree(hashes, func(left, right []byte) []byte {
+		digest := sha3.Sum512(append(append([]byte{}, left...), right...))
+		return digest[:]
+	})
+	expected := "031024:" + base64.StdEncoding.EncodeToString(tree[len(tree)-1])
+
+	for _, workers := range []int{1, 3, 16} {
+		var calls int
+		var hashed int64
+		params, _ := NewRegistrationParams("", nil)
+		opts := &FingerprintOptions{
+			Chunked:   true,
+			ChunkSize: 1024,
+			Workers:   workers,
+			Progress: func(h, total int64) {
+				assert.True(t, h > hashed)
+				assert.Equal(t, int64(-1), total)
+				hashed = h
+				calls++
+			},
+		}
+		assert.NoError(t, params.SetFingerprintFromReaderContext(context.Background(), bytes.NewReader(content), opts))
+		assert.Equal(t, expected, params.Fingerprint)
+		assert.Equal(t, 11, calls)
+		assert.Equal(t, int64(len(content)), hashed)
+	}
+
+	// a single chunk is its own root
+	params, _ := NewRegistrationParams("", nil)
+	opts := &FingerprintOptions{Chunked: true}
+	assert.NoError(t, params.SetFingerprintFromReaderContext(context.Background(), bytes.NewReader([]byte("hello world")), opts))
+	digest := sha3.Sum512([]byte("hello world"))
+	assert.Equal(t, "034194304:"+base64.StdEncoding.EncodeToString(digest[:]), params.Fingerprint)
+
+	assert.EqualError(t, params.SetFingerprintFromReaderContext(context.Background(), bytes.NewReader(nil), opts), ErrEmptyContent.Error())
+	opts.ChunkSize = -1
+	assert.EqualError(t, params.SetFingerprintFromReaderContext(context.Background(), bytes.NewReader(content), opts), ErrInvalidChunkSize.Error())
+}
+
+func TestFingerprintCancel(t *testing.T) {
+	content := make([]byte, 1024*1024)
+	params, _ := NewRegistrationParams("", nil)
+
+	for _, chunked := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		opts := &FingerprintOptions{
+			Chunked:   chunked,
+			ChunkSize: 1024,
+			Workers:   2,
+			Progress: func(hashed, total int64) {
+				if hashed >= 64*1024 {
+					cancel()
+				}
+			},
+		}
+		err := params.SetFingerprintFromReaderContext(ctx, bytes.NewReader(content), opts)
+		assert.Equal(t, context.Canceled, err)
+		cancel()
+	}
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidFingerprint = errors.New("fingerprint is not a merkle root")
+	ErrHashNotInTree      = errors.New("hash is not a leaf of the merkle tree")
+	ErrInvalidProof       = errors.New("inclusion proof does not match the fingerprint")
+	ErrFileNotInManifest  = errors.New("file not in manifest")
+)
+
+// ProofStep is the sibling of a node on the path from a leaf to the root
+type ProofStep struct {
+	Hash string `json:"hash"` // hex SHA3-512
+	Left bool   `json:"left"` // the sibling is the left node
+}
+
+// InclusionProof shows that a file hash is a leaf of the merkle tree of a
+// multi-file fingerprint, without revealing the other files
+type InclusionProof struct {
+	Hash  string      `json:"hash"` // hex SHA3-512 of the file
+	Steps []ProofStep `json:"steps"`
+}
+
+// NewInclusionProof builds the proof for hash among the file hashes of a multi-file
+// fingerprint, e.g. as passed to SetFingerprintFromDataArray in any order
+func NewInclusionProof(hashes [][]byte, hash []byte) (*InclusionProof, error) {
+	// the same order as setFingerprintFromHashes
+	level := make([][]byte, len(hashes))
+	copy(level, hashes)
+	sort.Slice(level, func(i, j int) bool { return bytes.Compare(level[i], level[j]) == -1 })
+
+	index := -1
+	for i, h := range level {
+		if bytes.Equal(h, hash) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrHashNotInTree
+	}
+
+	proof := &InclusionProof{Hash: hex.EncodeToString(hash), Steps: make([]ProofStep, 0)}
+	for len(level) > 1 {
+		var step ProofStep
+		if index%2 == 1 {
+			step = ProofStep{Hash: hex.EncodeToString(level[index-1]), Left: true}
+		} else if index+1 < len(level) {
+			step = ProofStep{Hash: hex.EncodeToString(level[index+1])}
+		} else {
+			// the last node of an odd level is paired with itself
+			step = ProofStep{Hash: hex.EncodeToString(level[index])}
+		}
+		proof.Steps = append(proof.Steps, step)
+
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			j := i + 1
+			if j == len(level) {
+				j = i
+			}
+			next = append(next, combineHashes(level[i], level[j]))
+		}
+		level = next
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// InclusionProof builds the proof that the file at path is part of the manifest fingerprint
+func (m *Manifest) InclusionProof(path string) (*InclusionProof, error) {
+	hashes := make([][]byte, len(m.Files))
+	var hash []byte
+	for i, f := range m.Files {
+		h, err := hex.DecodeString(f.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash of %s", f.Path)
+		}
+		hashes[i] = h
+		if f.Path == path {
+			hash = h
+		}
+	}
+	if hash == nil {
+		return nil, ErrFileNotInManifest
+	}
+
+	return NewInclusionProof(hashes, hash)
+}
+
+// Root computes the merkle root the proof leads to
+func (p *InclusionProof) Root() ([]byte, error) {
+	node, err := hex.DecodeString(p.Hash)
+	if err != nil {
+		return nil, ErrInvalidProof
+	}
+
+	for _, step := range p.Steps {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil {
+			return nil, ErrInvalidProof
+		}
+		if step.Left {
+			node = combineHashes(sibling, node)
+		} else {
+			node = combineHashes(node, sibling)
+		}
+	}
+	return node, nil
+}
+
+// VerifyInclusionProof checks the proof against a "02" prefixed multi-file fingerprint
+func VerifyInclusionProof(fingerprint string, proof *InclusionProof) error {
+	prefix := fmt.Sprintf("%02d", fingerprintTypeMerkleTree)
+	if !strings.HasPrefix(fingerprint, prefix) {
+		return ErrInvalidFingerprint
+	}
+	root, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(fingerprint, prefix))
+	if err != nil {
+		return ErrInvalidFingerprint
+	}
+
+	actual, err := proof.Root()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, actual) {
+		return ErrInvalidProof
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestInclusionProof(t *testing.T) {
+	// odd sizes exercise the duplicated last node at different levels
+	for _, count := range []int{1, 2, 3, 5, 7, 10} {
+		contents := make([][]byte, count)
+		hashes := make([][]byte, count)
+		for i := range contents {
+			contents[i] = []byte(fmt.Sprintf("file %d", i))
+			digest := sha3.Sum512(contents[i])
+			hashes[i] = digest[:]
+		}
+
+		params, _ := NewRegistrationParams("", nil)
+		assert.NoError(t, params.SetFingerprintFromDataArray(contents))
+
+		for _, hash := range hashes {
+			proof, err := NewInclusionProof(hashes, hash)
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyInclusionProof(params.Fingerprint, proof), "count %d", count)
+		}
+	}
+
+	digest := sha3.Sum512([]byte("missing"))
+	_, err := NewInclusionProof([][]byte{digest[:1]}, digest[:])
+	assert.EqualError(t, err, ErrHashNotInTree.Error())
+}
+
+func TestInclusionProofTampered(t *testing.T) {
+	params, _ := NewRegistrationParams("", nil)
+	contents := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	assert.NoError(t, params.SetFingerprintFromDataArray(contents))
+
+	hashes := make([][]byte, len(contents))
+	for i := range contents {
+		digest := sha3.Sum512(contents[i])
+		hashes[i] = digest[:]
+	}
+	proof, err := NewInclusionProof(hashes, hashes[2])
+	assert.NoError(t, err)
+	assert.Len(t, proof.Steps, 2)
+
+	proof.Steps[1].Left = !proof.Steps[1].Left
+	assert.EqualError(t, VerifyInclusionProof(params.Fingerprint, proof), ErrInvalidProof.Error())
+	proof.Steps[1].Left = !proof.Steps[1].Left
+	assert.NoError(t, VerifyInclusionProof(params.Fingerprint, proof))
+
+	assert.EqualError(t, VerifyInclusionProof("01abcd", proof), ErrInvalidFingerprint.Error())
+	assert.EqualError(t, VerifyInclusionProof("02%%%", proof), ErrInvalidFingerprint.Error())
+	proof.Hash = "zz"
+	assert.EqualError(t, VerifyInclusionProof(params.Fingerprint, proof), ErrInvalidProof.Error())
+}
+
+func TestManifestInclusionProof(t *testing.T) {
+	params, _ := NewRegistrationParams("", nil)
+	manifest, err := params.SetFingerprintFromTar(bytes.NewReader(newTestTar(t)), nil)
+	assert.NoError(t, err)
+
+	for _, f := range manifest.Files {
+		proof, err := manifest.InclusionProof(f.Path)
+		assert.NoError(t, err)
+		assert.Equal(t, f.Hash, proof.Hash)
+		assert.NoError(t, VerifyInclusionProof(manifest.Fingerprint, proof))
+	}
+
+	_, err = manifest.InclusionProof("nothing")
+	assert.EqualError(t, err, ErrFileNotInManifest.Error())
+}
@@ -0,0 +1,416 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// maximum number of links followed to reach a file
+const maxSymlinkHops = 40
+
+type SymlinkPolicy int
+
+const (
+	// SkipSymlinks leaves symbolic links out of the fingerprint
+	SkipSymlinks SymlinkPolicy = iota
+	// FollowSymlinks fingerprints the file a link points to under the link's path;
+	// in archives the target must be a file in the same archive
+	FollowSymlinks
+	// RejectSymlinks fails on the first symbolic link
+	RejectSymlinks
+)
+
+var (
+	ErrSymlinkRejected   = errors.New("symbolic link rejected")
+	ErrDanglingSymlink   = errors.New("symbolic link target not found")
+	ErrSymlinkLoop       = errors.New("too many levels of symbolic links")
+	ErrInvalidGlob       = errors.New("invalid glob pattern")
+	ErrUnsupportedFormat = errors.New("unsupported archive entry")
+)
+
+// TreeOptions select the files fingerprinted from a directory or an archive
+type TreeOptions struct {
+	// Include lists glob patterns, as in path.Match, of the files to fingerprint;
+	// all files are included if empty. A pattern without a "/" matches the base
+	// name, otherwise the slash separated path relative to the root.
+	Include []string
+	// Exclude lists glob patterns of the files and directories to leave out,
+	// it takes precedence over Include
+	Exclude []string
+	// Symlinks is the handling of symbolic links, SkipSymlinks by default
+	Symlinks SymlinkPolicy
+}
+
+// ManifestEntry is a single file of a multi-file asset
+type ManifestEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"` // hex SHA3-512 of the content
+}
+
+// Manifest lists the files of a multi-file asset ordered by path, it can be
+// stored alongside the asset to tell which file a hash belongs to
+type Manifest struct {
+	Fingerprint string          `json:"fingerprint"`
+	Files       []ManifestEntry `json:"files"`
+}
+
+// SetFingerprintFromDir fingerprints all files under root the same way as
+// SetFingerprintFromReaders and returns the manifest of the files
+func (r *RegistrationParams) SetFingerprintFromDir(root string, opts *TreeOptions) (*Manifest, error) {
+	t, err := newTreeHasher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.walkDir(root, "", make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return t.finish(r)
+}
+
+// SetFingerprintFromTar fingerprints the files of a tar archive
+func (r *RegistrationParams) SetFingerprintFromTar(reader io.Reader, opts *TreeOptions) (*Manifest, error) {
+	t, err := newTreeHasher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := cleanArchivePath(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+			if err := t.addFile(name, tr); err != nil {
+				return nil, err
+			}
+		case tar.TypeLink:
+			// a hard link is the same file, whatever the symlink policy
+			t.addLink(name, "/"+cleanArchivePath(hdr.Linkname), true)
+		case tar.TypeSymlink:
+			if err := t.addSymlink(name, hdr.Linkname); err != nil {
+				return nil, err
+			}
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+		default:
+			return nil, ErrUnsupportedFormat
+		}
+	}
+	return t.finish(r)
+}
+
+// SetFingerprintFromZip fingerprints the files of a zip archive
+func (r *RegistrationParams) SetFingerprintFromZip(reader io.ReaderAt, size int64, opts *TreeOptions) (*Manifest, error) {
+	t, err := newTreeHasher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zr.File {
+		name := cleanArchivePath(f.Name)
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+		case mode&os.ModeSymlink != 0:
+			target, err := readZipFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if err := t.addSymlink(name, string(target)); err != nil {
+				return nil, err
+			}
+		case mode.IsRegular():
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			err = t.addFile(name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, ErrUnsupportedFormat
+		}
+	}
+	return t.finish(r)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func cleanArchivePath(name string) string {
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+type treeLink struct {
+	target string // slash path from the archive root
+	hard   bool
+}
+
+// treeHasher collects the files and links of a tree; for archives they are
+// kept whether selected or not, so a selected link can point to any file
+type treeHasher struct {
+	opts  TreeOptions
+	files map[string]ManifestEntry
+	links map[string]treeLink
+}
+
+func newTreeHasher(opts *TreeOptions) (*treeHasher, error) {
+	t := &treeHasher{
+		files: make(map[string]ManifestEntry),
+		links: make(map[string]treeLink),
+	}
+	if opts != nil {
+		t.opts = *opts
+	}
+
+	// reject bad patterns up front rather than silently matching nothing
+	for _, pattern := range append(append([]string{}, t.opts.Include...), t.opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, ErrInvalidGlob
+		}
+	}
+	return t, nil
+}
+
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func (t *treeHasher) excluded(name string) bool {
+	for _, pattern := range t.opts.Exclude {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *treeHasher) selected(name string) bool {
+	if name == "" || t.excluded(name) {
+		return false
+	}
+	// files under an excluded directory of an archive
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		if t.excluded(dir) {
+			return false
+		}
+	}
+
+	if len(t.opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range t.opts.Include {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *treeHasher) addFile(name string, reader io.Reader) error {
+	h := sha3.New512()
+	size, err := io.Copy(h, reader)
+	if err != nil {
+		return err
+	}
+
+	t.files[name] = ManifestEntry{Path: name, Size: size, Hash: hex.EncodeToString(h.Sum(nil))}
+	delete(t.links, name)
+	return nil
+}
+
+func (t *treeHasher) addSymlink(name, target string) error {
+	switch t.opts.Symlinks {
+	case RejectSymlinks:
+		if !t.selected(name) {
+			return nil
+		}
+		return fmt.Errorf("%s: %s", name, ErrSymlinkRejected)
+	case FollowSymlinks:
+		if !path.IsAbs(target) {
+			target = path.Join("/", path.Dir(name), target)
+		}
+		t.addLink(name, target, false)
+	}
+	return nil
+}
+
+func (t *treeHasher) addLink(name, target string, hard bool) {
+	t.links[name] = treeLink{target: cleanArchivePath(target), hard: hard}
+	delete(t.files, name)
+}
+
+// walkDir hashes the directory entries in name order; ancestors holds the real paths
+// of the directories being walked so a link back to one of them is detected
+func (t *treeHasher) walkDir(dir, rel string, ancestors map[string]bool) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if ancestors[real] {
+		return ErrSymlinkLoop
+	}
+	ancestors[real] = true
+	defer delete(ancestors, real)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range entries {
+		name := path.Join(rel, info.Name())
+		full := filepath.Join(dir, info.Name())
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if t.excluded(name) {
+				continue
+			}
+			switch t.opts.Symlinks {
+			case SkipSymlinks:
+				continue
+			case RejectSymlinks:
+				return fmt.Errorf("%s: %s", name, ErrSymlinkRejected)
+			}
+
+			target, err := os.Stat(full)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s: %s", name, ErrDanglingSymlink)
+			}
+			if err != nil {
+				return err
+			}
+			if target.IsDir() {
+				if err := t.walkDir(full, name, ancestors); err != nil {
+					return err
+				}
+				continue
+			}
+			info = target
+		}
+
+		if info.IsDir() {
+			if t.excluded(name) {
+				continue
+			}
+			if err := t.walkDir(full, name, ancestors); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() || !t.selected(name) {
+			continue
+		}
+
+		f, err := os.Open(full)
+		if err != nil {
+			return err
+		}
+		err = t.addFile(name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *treeHasher) finish(r *RegistrationParams) (*Manifest, error) {
+	selected := make(map[string]ManifestEntry)
+	for name, entry := range t.files {
+		if t.selected(name) {
+			selected[name] = entry
+		}
+	}
+	// links are resolved against the whole tree, only the link itself has to be selected
+	for name, link := range t.links {
+		if !t.selected(name) {
+			continue
+		}
+		entry, err := t.resolve(link)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		entry.Path = name
+		selected[name] = entry
+	}
+
+	if len(selected) == 0 {
+		return nil, ErrEmptyContent
+	}
+
+	manifest := &Manifest{Files: make([]ManifestEntry, 0, len(selected))}
+	for _, entry := range selected {
+		manifest.Files = append(manifest.Files, entry)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+
+	hashes := make([][]byte, len(manifest.Files))
+	for i, entry := range manifest.Files {
+		hashes[i], _ = hex.DecodeString(entry.Hash)
+	}
+	if err := r.setFingerprintFromHashes(hashes); err != nil {
+		return nil, err
+	}
+
+	manifest.Fingerprint = r.Fingerprint
+	return manifest, nil
+}
+
+// resolve finds the file an archive link points to, the target may be any file
+// of the archive, selected or not
+func (t *treeHasher) resolve(link treeLink) (ManifestEntry, error) {
+	for hops := 0; hops < maxSymlinkHops; hops++ {
+		if entry, ok := t.files[link.target]; ok {
+			return entry, nil
+		}
+		next, ok := t.links[link.target]
+		if !ok {
+			return ManifestEntry{}, ErrDanglingSymlink
+		}
+		link = next
+	}
+	return ManifestEntry{}, ErrSymlinkLoop
+}
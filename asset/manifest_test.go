@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var treeFiles = map[string]string{
+	"a.txt":     "hello world",
+	"sub/b.txt": "this is a test case",
+	"sub/c.log": "bitmark sdk",
+}
+
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "manifest")
+	assert.NoError(t, err)
+	for name, content := range treeFiles {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		assert.NoError(t, ioutil.WriteFile(full, []byte(content), 0644))
+	}
+	assert.NoError(t, os.Symlink("a.txt", filepath.Join(dir, "link.txt")))
+	return dir
+}
+
+func newTestTar(t *testing.T) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "./sub/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, name := range []string{"sub/c.log", "a.txt", "sub/b.txt"} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(treeFiles[name]))}))
+		tw.Write([]byte(treeFiles[name]))
+	}
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "./link.txt", Typeflag: tar.TypeSymlink, Linkname: "a.txt"}))
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func newTestZip(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"sub/b.txt", "a.txt", "sub/c.log"} {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(treeFiles[name]))
+	}
+	hdr := &zip.FileHeader{Name: "sub/up.txt"}
+	hdr.SetMode(os.ModeSymlink | 0777)
+	w, err := zw.CreateHeader(hdr)
+	assert.NoError(t, err)
+	w.Write([]byte("../a.txt"))
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestSetFingerprintFromDir(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	// the same fingerprint as the contents fingerprinted as a data array
+	expected, _ := NewRegistrationParams("", nil)
+	assert.NoError(t, expected.SetFingerprintFromDataArray([][]byte{
+		[]byte("hello world"),
+		[]byte("this is a test case"),
+		[]byte("bitmark sdk"),
+	}))
+
+	params, _ := NewRegistrationParams("", nil)
+	manifest, err := params.SetFingerprintFromDir(dir, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Fingerprint, params.Fingerprint)
+	assert.Equal(t, params.Fingerprint, manifest.Fingerprint)
+	assert.Equal(t, []string{"a.txt", "sub/b.txt", "sub/c.log"}, manifestPaths(manifest))
+	assert.Equal(t, int64(11), manifest.Files[0].Size)
+	assert.Equal(t, "840006653e9ac9e95117a15c915caab81662918e925de9e004f774ff82d7079a40d4d27b1b372657c61d46d470304c88c788b3a4527ad074d1dccbee5dbaa99a", manifest.Files[0].Hash)
+
+	manifest, err = params.SetFingerprintFromDir(dir, &TreeOptions{Include: []string{"*.txt"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "sub/b.txt"}, manifestPaths(manifest))
+
+	manifest, err = params.SetFingerprintFromDir(dir, &TreeOptions{Exclude: []string{"sub"}, Symlinks: FollowSymlinks})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "link.txt"}, manifestPaths(manifest))
+	assert.Equal(t, manifest.Files[0].Hash, manifest.Files[1].Hash)
+
+	// the link is followed even though its target is excluded
+	manifest, err = params.SetFingerprintFromDir(dir, &TreeOptions{Exclude: []string{"a.txt"}, Symlinks: FollowSymlinks})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"link.txt", "sub/b.txt", "sub/c.log"}, manifestPaths(manifest))
+
+	_, err = params.SetFingerprintFromDir(dir, &TreeOptions{Symlinks: RejectSymlinks})
+	assert.EqualError(t, err, "link.txt: "+ErrSymlinkRejected.Error())
+
+	_, err = params.SetFingerprintFromDir(dir, &TreeOptions{Include: []string{"*.png"}})
+	assert.EqualError(t, err, ErrEmptyContent.Error())
+
+	_, err = params.SetFingerprintFromDir(dir, &TreeOptions{Include: []string{"["}})
+	assert.EqualError(t, err, ErrInvalidGlob.Error())
+
+	// a link back to its own directory
+	assert.NoError(t, os.Symlink(".", filepath.Join(dir, "sub", "loop")))
+	_, err = params.SetFingerprintFromDir(dir, &TreeOptions{Symlinks: FollowSymlinks})
+	assert.EqualError(t, err, ErrSymlinkLoop.Error())
+}
+
+func TestSetFingerprintFromArchives(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	params, _ := NewRegistrationParams("", nil)
+	fromDir, err := params.SetFingerprintFromDir(dir, &TreeOptions{Symlinks: FollowSymlinks})
+	assert.NoError(t, err)
+
+	fromTar, err := params.SetFingerprintFromTar(bytes.NewReader(newTestTar(t)), &TreeOptions{Symlinks: FollowSymlinks})
+	assert.NoError(t, err)
+	assert.Equal(t, fromDir, fromTar)
+
+	fromTar, err = params.SetFingerprintFromTar(bytes.NewReader(newTestTar(t)), &TreeOptions{Exclude: []string{"sub"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, manifestPaths(fromTar))
+
+	_, err = params.SetFingerprintFromTar(bytes.NewReader(newTestTar(t)), &TreeOptions{Symlinks: RejectSymlinks})
+	assert.EqualError(t, err, "link.txt: "+ErrSymlinkRejected.Error())
+
+	z := newTestZip(t)
+	fromZip, err := params.SetFingerprintFromZip(bytes.NewReader(z), int64(len(z)), &TreeOptions{Symlinks: FollowSymlinks})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "sub/b.txt", "sub/c.log", "sub/up.txt"}, manifestPaths(fromZip))
+	assert.Equal(t, fromZip.Files[0].Hash, fromZip.Files[3].Hash)
+
+	// a link to an excluded file is resolved against the whole archive
+	hash := fromZip.Files[0].Hash
+	fromZip, err = params.SetFingerprintFromZip(bytes.NewReader(z), int64(len(z)), &TreeOptions{Symlinks: FollowSymlinks, Exclude: []string{"a.txt"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sub/b.txt", "sub/c.log", "sub/up.txt"}, manifestPaths(fromZip))
+	assert.Equal(t, hash, fromZip.Files[2].Hash)
+
+	fromTar, err = params.SetFingerprintFromTar(bytes.NewReader(newTestTar(t)), &TreeOptions{Symlinks: FollowSymlinks, Include: []string{"link.txt"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"link.txt"}, manifestPaths(fromTar))
+	assert.Equal(t, hash, fromTar.Files[0].Hash)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "dangling.txt", Typeflag: tar.TypeSymlink, Linkname: "missing.txt"}))
+	assert.NoError(t, tw.Close())
+	_, err = params.SetFingerprintFromTar(&buf, &TreeOptions{Symlinks: FollowSymlinks})
+	assert.EqualError(t, err, "dangling.txt: "+ErrDanglingSymlink.Error())
+
+	buf.Reset()
+	tw = tar.NewWriter(&buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "pipe", Typeflag: tar.TypeFifo, Mode: 0644}))
+	assert.NoError(t, tw.Close())
+	_, err = params.SetFingerprintFromTar(&buf, nil)
+	assert.Equal(t, ErrUnsupportedFormat, err)
+
+	fromZip, err = params.SetFingerprintFromZip(bytes.NewReader(z), int64(len(z)), nil)
+	assert.NoError(t, err)
+	fromDir, err = params.SetFingerprintFromDir(dir, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fromDir, fromZip)
+}
+
+func manifestPaths(m *Manifest) []string {
+	paths := make([]string, len(m.Files))
+	for i, f := range m.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
@@ -4,6 +4,17 @@
 
 package asset
 
+import "golang.org/x/crypto/sha3"
+
+// combineHashes is the parent of two nodes in the fingerprint merkle trees
+func combineHashes(left, right []byte) []byte {
+	data := make([]byte, 0, len(left)+len(right))
+	data = append(data, left...)
+	data = append(data, right...)
+	hash := sha3.Sum512(data)
+	return hash[:]
+}
+
 func buildMerkleTree(txIds [][]byte, combineFunc func(left, right []byte) []byte) [][]byte {
 	// compute length of ids + all tree levels including root
 	idCount := len(txIds)
@@ -0,0 +1,372 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// well known metadata keys
+const (
+	MetadataSource    = "source"
+	MetadataSavedTime = "saved_time"
+	MetadataMimeType  = "mime_type"
+	MetadataCreator   = "creator"
+	MetadataLicense   = "license"
+)
+
+const metadataSeparator = "\u0000"
+
+var (
+	ErrSchemaExists       = errors.New("metadata schema already registered")
+	ErrUnknownSchema      = errors.New("metadata schema not registered")
+	ErrInvalidSchema      = errors.New("invalid metadata schema")
+	ErrMetadataIsNotMap   = errors.New("metadata is not a list of key value pairs")
+	ErrDuplicatedMetadata = errors.New("duplicated metadata key")
+)
+
+// MetadataError reports a metadata key which is missing or has an invalid value
+type MetadataError struct {
+	Key    string
+	Reason string
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("metadata %q: %s", e.Key, e.Reason)
+}
+
+// MetadataBudget is the size of the compact metadata against the limit bitmarkd
+// enforces, which counts Unicode characters
+type MetadataBudget struct {
+	Characters int `json:"characters"`
+	Bytes      int `json:"bytes"`
+	Limit      int `json:"limit"`
+	Remaining  int `json:"remaining"` // negative when over the limit
+}
+
+type MetadataBuilder struct {
+	fields map[string]string
+	schema string
+	err    error
+}
+
+func NewMetadataBuilder() *MetadataBuilder {
+	return &MetadataBuilder{fields: make(map[string]string)}
+}
+
+func (b *MetadataBuilder) Source(source string) *MetadataBuilder {
+	return b.set(MetadataSource, source)
+}
+
+// SavedTime is stored in RFC 3339 format, in UTC
+func (b *MetadataBuilder) SavedTime(t time.Time) *MetadataBuilder {
+	return b.set(MetadataSavedTime, t.UTC().Format(time.RFC3339))
+}
+
+func (b *MetadataBuilder) MimeType(mimeType string) *MetadataBuilder {
+	if _, _, err := mime.ParseMediaType(mimeType); err != nil {
+		b.fail(&MetadataError{Key: MetadataMimeType, Reason: "invalid mime type"})
+		return b
+	}
+	return b.set(MetadataMimeType, mimeType)
+}
+
+func (b *MetadataBuilder) Creator(creator string) *MetadataBuilder {
+	return b.set(MetadataCreator, creator)
+}
+
+func (b *MetadataBuilder) License(license string) *MetadataBuilder {
+	return b.set(MetadataLicense, license)
+}
+
+// Custom adds any other key, the well known keys must be set with their own methods
+func (b *MetadataBuilder) Custom(key, value string) *MetadataBuilder {
+	switch key {
+	case MetadataSource, MetadataSavedTime, MetadataMimeType, MetadataCreator, MetadataLicense, string(DHash), string(PHash), MetadataCID:
+		b.fail(&MetadataError{Key: key, Reason: "reserved key"})
+		return b
+	}
+	return b.set(key, value)
+}
+
+// Schema validates the metadata against a registered schema when it is built
+func (b *MetadataBuilder) Schema(name string) *MetadataBuilder {
+	b.schema = name
+	return b
+}
+
+func (b *MetadataBuilder) set(key, value string) *MetadataBuilder {
+	if err := checkMetadataPair(key, value); err != nil {
+		b.fail(err)
+		return b
+	}
+	b.fields[key] = value
+	return b
+}
+
+func (b *MetadataBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build returns the metadata map or the first error found
+func (b *MetadataBuilder) Build() (map[string]string, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.schema != "" {
+		schema, ok := LookupMetadataSchema(b.schema)
+		if !ok {
+			return nil, ErrUnknownSchema
+		}
+		if err := schema.Validate(b.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	if budget := b.Budget(); budget.Remaining < 0 {
+		return nil, ErrInvalidMetadataLength
+	}
+
+	fields := make(map[string]string, len(b.fields))
+	for k, v := range b.fields {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+// Compact builds the metadata in the form stored on chain
+func (b *MetadataBuilder) Compact() (string, error) {
+	fields, err := b.Build()
+	if err != nil {
+		return "", err
+	}
+	return compactMetadata(fields), nil
+}
+
+// Budget reports the size of the metadata built so far
+func (b *MetadataBuilder) Budget() MetadataBudget {
+	return metadataBudget(compactMetadata(b.fields))
+}
+
+func metadataBudget(compact string) MetadataBudget {
+	characters := utf8.RuneCountInString(compact)
+	return MetadataBudget{
+		Characters: characters,
+		Bytes:      len(compact),
+		Limit:      maxMetadataLength,
+		Remaining:  maxMetadataLength - characters,
+	}
+}
+
+func checkMetadataPair(key, value string) error {
+	switch {
+	case key == "":
+		return &MetadataError{Key: key, Reason: "empty key"}
+	case value == "":
+		return &MetadataError{Key: key, Reason: "empty value"}
+	case strings.Contains(key, metadataSeparator) || strings.Contains(value, metadataSeparator):
+		return &MetadataError{Key: key, Reason: "contains a NUL character"}
+	case !utf8.ValidString(key) || !utf8.ValidString(value):
+		return &MetadataError{Key: key, Reason: "invalid UTF-8"}
+	}
+	return nil
+}
+
+// compactMetadata joins the pairs ordered by key with NUL characters
+func compactMetadata(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(fields)*2)
+	for _, key := range keys {
+		parts = append(parts, key, fields[key])
+	}
+	return strings.Join(parts, metadataSeparator)
+}
+
+// ParseMetadata reads the compact on chain form back into a map
+func ParseMetadata(compact string) (map[string]string, error) {
+	fields := make(map[string]string)
+	if compact == "" {
+		return fields, nil
+	}
+
+	parts := strings.Split(compact, metadataSeparator)
+	if len(parts)%2 != 0 {
+		return nil, ErrMetadataIsNotMap
+	}
+	for i := 0; i < len(parts); i += 2 {
+		if parts[i] == "" || parts[i+1] == "" {
+			return nil, ErrMetadataIsNotMap
+		}
+		if _, ok := fields[parts[i]]; ok {
+			return nil, ErrDuplicatedMetadata
+		}
+		fields[parts[i]] = parts[i+1]
+	}
+	return fields, nil
+}
+
+// SetMetadata replaces the metadata of the registration with the built metadata
+func (r *RegistrationParams) SetMetadata(b *MetadataBuilder) error {
+	compact, err := b.Compact()
+	if err != nil {
+		return err
+	}
+	r.Metadata = compact
+	return nil
+}
+
+// MetadataBudget reports the size of the registration metadata
+func (r *RegistrationParams) MetadataBudget() MetadataBudget {
+	return metadataBudget(r.Metadata)
+}
+
+type MetadataType int
+
+const (
+	MetadataString MetadataType = iota
+	MetadataInteger
+	MetadataTime // RFC 3339
+	MetadataURL
+	MetadataMime
+)
+
+// SchemaField describes one metadata key
+type SchemaField struct {
+	Key       string
+	Type      MetadataType
+	Required  bool
+	MaxLength int      // in characters, unlimited if 0
+	Values    []string // allowed values, any if empty
+}
+
+// MetadataSchema describes the metadata expected for a kind of asset
+type MetadataSchema struct {
+	Name         string
+	Fields       []SchemaField
+	AllowUnknown bool // accept keys which are not in Fields
+}
+
+var (
+	schemas     = make(map[string]*MetadataSchema)
+	schemasLock sync.RWMutex
+)
+
+// RegisterMetadataSchema makes a schema available to MetadataBuilder.Schema
+func RegisterMetadataSchema(schema *MetadataSchema) error {
+	if schema == nil || schema.Name == "" {
+		return ErrInvalidSchema
+	}
+	for _, f := range schema.Fields {
+		if f.Key == "" {
+			return ErrInvalidSchema
+		}
+	}
+
+	schemasLock.Lock()
+	defer schemasLock.Unlock()
+
+	if _, ok := schemas[schema.Name]; ok {
+		return ErrSchemaExists
+	}
+	schemas[schema.Name] = schema
+	return nil
+}
+
+func LookupMetadataSchema(name string) (*MetadataSchema, bool) {
+	schemasLock.RLock()
+	defer schemasLock.RUnlock()
+
+	schema, ok := schemas[name]
+	return schema, ok
+}
+
+// Validate checks metadata, as built or as parsed from an asset, against the schema
+func (s *MetadataSchema) Validate(metadata map[string]string) error {
+	known := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		known[f.Key] = true
+
+		value, ok := metadata[f.Key]
+		if !ok {
+			if f.Required {
+				return &MetadataError{Key: f.Key, Reason: "required"}
+			}
+			continue
+		}
+		if err := f.validate(value); err != nil {
+			return err
+		}
+	}
+
+	if !s.AllowUnknown {
+		keys := make([]string, 0, len(metadata))
+		for k := range metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !known[k] {
+				return &MetadataError{Key: k, Reason: "not in schema " + s.Name}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *SchemaField) validate(value string) error {
+	if f.MaxLength > 0 && utf8.RuneCountInString(value) > f.MaxLength {
+		return &MetadataError{Key: f.Key, Reason: fmt.Sprintf("longer than %d characters", f.MaxLength)}
+	}
+
+	if len(f.Values) > 0 {
+		allowed := false
+		for _, v := range f.Values {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &MetadataError{Key: f.Key, Reason: "value not allowed"}
+		}
+	}
+
+	var err error
+	switch f.Type {
+	case MetadataInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case MetadataTime:
+		_, err = time.Parse(time.RFC3339, value)
+	case MetadataURL:
+		var u *url.URL
+		u, err = url.Parse(value)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = errors.New("not an absolute URL")
+		}
+	case MetadataMime:
+		_, _, err = mime.ParseMediaType(value)
+	}
+	if err != nil {
+		return &MetadataError{Key: f.Key, Reason: "invalid value"}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataBuilder(t *testing.T) {
+	savedTime := time.Date(2020, 5, 1, 10, 30, 0, 0, time.FixedZone("ICT", 7*3600))
+
+	compact, err := NewMetadataBuilder().
+		Source("camera").
+		SavedTime(savedTime).
+		MimeType("image/png").
+		Creator("Alice").
+		License("CC-BY-4.0").
+		Custom("width", "1024").
+		Compact()
+	assert.NoError(t, err)
+	assert.Equal(t, "creator\u0000Alice\u0000license\u0000CC-BY-4.0\u0000mime_type\u0000image/png\u0000"+
+		"saved_time\u00002020-05-01T03:30:00Z\u0000source\u0000camera\u0000width\u00001024", compact)
+
+	metadata, err := ParseMetadata(compact)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"creator":    "Alice",
+		"license":    "CC-BY-4.0",
+		"mime_type":  "image/png",
+		"saved_time": "2020-05-01T03:30:00Z",
+		"source":     "camera",
+		"width":      "1024",
+	}, metadata)
+
+	// same form as NewRegistrationParams
+	params, err := NewRegistrationParams("name", metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, compact, params.Metadata)
+}
+
+func TestMetadataBuilderInvalid(t *testing.T) {
+	testCases := []struct {
+		builder *MetadataBuilder
+		key     string
+	}{
+		{NewMetadataBuilder().Custom("", "value"), ""},
+		{NewMetadataBuilder().Custom("key", ""), "key"},
+		{NewMetadataBuilder().Custom("key", "a\u0000b"), "key"},
+		{NewMetadataBuilder().Custom("source", "camera"), "source"},
+		{NewMetadataBuilder().MimeType("not a mime type"), "mime_type"},
+		{NewMetadataBuilder().Creator("").Source("camera"), "creator"},
+	}
+
+	for _, c := range testCases {
+		_, err := c.builder.Build()
+		if assert.IsType(t, &MetadataError{}, err) {
+			assert.Equal(t, c.key, err.(*MetadataError).Key)
+		}
+	}
+}
+
+func TestMetadataBudget(t *testing.T) {
+	b := NewMetadataBuilder().Custom("k", "ñ")
+	assert.Equal(t, MetadataBudget{Characters: 3, Bytes: 4, Limit: 2048, Remaining: 2045}, b.Budget())
+
+	// the limit is in characters, not bytes
+	b = NewMetadataBuilder().Custom("k", strings.Repeat("ñ", 2046))
+	assert.Equal(t, 0, b.Budget().Remaining)
+	_, err := b.Build()
+	assert.NoError(t, err)
+
+	b.Custom("l", "x")
+	assert.Equal(t, -4, b.Budget().Remaining)
+	_, err = b.Build()
+	assert.Equal(t, ErrInvalidMetadataLength, err)
+
+	params := &RegistrationParams{}
+	assert.Equal(t, ErrInvalidMetadataLength, params.SetMetadata(b))
+	assert.NoError(t, params.SetMetadata(NewMetadataBuilder().Custom("k", "ñ")))
+	assert.Equal(t, "k\u0000ñ", params.Metadata)
+	assert.Equal(t, 2045, params.MetadataBudget().Remaining)
+}
+
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata("")
+	assert.NoError(t, err)
+	assert.Empty(t, metadata)
+
+	_, err = ParseMetadata("a\u0000b\u0000c")
+	assert.Equal(t, ErrMetadataIsNotMap, err)
+
+	_, err = ParseMetadata("a\u0000\u0000c\u0000d")
+	assert.Equal(t, ErrMetadataIsNotMap, err)
+
+	_, err = ParseMetadata("a\u0000b\u0000a\u0000c")
+	assert.Equal(t, ErrDuplicatedMetadata, err)
+}
+
+func TestMetadataSchema(t *testing.T) {
+	schema := &MetadataSchema{
+		Name: "photo",
+		Fields: []SchemaField{
+			{Key: MetadataSource, Type: MetadataURL, Required: true},
+			{Key: MetadataSavedTime, Type: MetadataTime, Required: true},
+			{Key: MetadataMimeType, Type: MetadataMime, Values: []string{"image/png", "image/jpeg"}},
+			{Key: "width", Type: MetadataInteger},
+			{Key: MetadataCreator, MaxLength: 5},
+		},
+	}
+	assert.NoError(t, RegisterMetadataSchema(schema))
+	assert.Equal(t, ErrSchemaExists, RegisterMetadataSchema(schema))
+	assert.Equal(t, ErrInvalidSchema, RegisterMetadataSchema(&MetadataSchema{}))
+	assert.Equal(t, ErrInvalidSchema, RegisterMetadataSchema(&MetadataSchema{Name: "x", Fields: []SchemaField{{}}}))
+
+	valid := func() *MetadataBuilder {
+		return NewMetadataBuilder().
+			Schema("photo").
+			Source("https://example.com/a.png").
+			SavedTime(time.Unix(0, 0))
+	}
+	_, err := valid().MimeType("image/png").Custom("width", "12").Creator("Alice").Build()
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		builder *MetadataBuilder
+		key     string
+		reason  string
+	}{
+		{NewMetadataBuilder().Schema("photo").Source("https://example.com"), MetadataSavedTime, "required"},
+		{valid().Source("camera"), MetadataSource, "invalid value"},
+		{valid().MimeType("image/gif"), MetadataMimeType, "value not allowed"},
+		{valid().Custom("width", "wide"), "width", "invalid value"},
+		{valid().Creator("Alice Bob"), MetadataCreator, "longer than 5 characters"},
+		{valid().Custom("height", "12"), "height", "not in schema photo"},
+	}
+	for _, c := range testCases {
+		_, err := c.builder.Build()
+		assert.Equal(t, &MetadataError{Key: c.key, Reason: c.reason}, err)
+	}
+
+	_, err = NewMetadataBuilder().Schema("video").Build()
+	assert.Equal(t, ErrUnknownSchema, err)
+
+	// metadata read from an asset can be checked too
+	s, _ := LookupMetadataSchema("photo")
+	assert.Error(t, s.Validate(map[string]string{"source": "https://example.com"}))
+}
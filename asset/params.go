@@ -11,7 +11,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"sort"
 	"strings"
 	"unicode/utf8"
@@ -30,23 +29,41 @@ const (
 	fingerprintTypeUserDefined = iota
 	fingerprintTypeSHA3512
 	fingerprintTypeMerkleTree
+	fingerprintTypeChunkedMerkleTree
 )
 
 var (
 	ErrInvalidNameLength     = errors.New("property name not set or exceeds the maximum length (64 Unicode characters)")
-	ErrInvalidMetadataLength = errors.New("property metadata exceeds the maximum length (1024 Unicode characters)")
+	ErrInvalidMetadataLength = errors.New("property metadata exceeds the maximum length (2048 Unicode characters)")
 	ErrEmptyContent          = errors.New("asset content is empty")
 	ErrNullRegistrant        = errors.New("registrant is null")
 )
 
 type RegistrationParams struct {
-	Name        string `json:"name" pack:"utf8"`
-	Fingerprint string `json:"fingerprint" pack:"utf8"`
-	Metadata    string `json:"metadata" pack:"utf8"`
-	Registrant  string `json:"registrant" pack:"account"`
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	Metadata    string `json:"metadata"`
+	Registrant  string `json:"registrant"`
 	Signature   string `json:"signature"`
 }
 
+func (r *RegistrationParams) RecordTag() uint64 {
+	return utils.RegisterTag
+}
+
+func (r *RegistrationParams) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.UTF8Field("Name", &r.Name),
+		utils.UTF8Field("Fingerprint", &r.Fingerprint),
+		utils.UTF8Field("Metadata", &r.Metadata),
+		utils.AccountField("Registrant", &r.Registrant),
+	}
+}
+
+func (r *RegistrationParams) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
+}
+
 func NewRegistrationParams(name string, metadata map[string]string) (*RegistrationParams, error) {
 	keys := make([]string, 0, len(metadata))
 	for k := range metadata {
@@ -78,15 +95,6 @@ func NewRegistrationParams(name string, metadata map[string]string) (*Registrati
 	}, nil
 }
 
-func (r *RegistrationParams) SetFingerprintFromFile(name string) error {
-	content, err := ioutil.ReadFile(name)
-	if err != nil {
-		return err
-	}
-
-	return r.SetFingerprintFromData(content)
-}
-
 func (r *RegistrationParams) SetFingerprintFromReader(reader io.Reader) error {
 	h := sha3.New512()
 	if _, err := io.Copy(h, reader); err != nil {
@@ -141,11 +149,7 @@ func (r *RegistrationParams) setFingerprintFromHashes(hashes [][]byte) error {
 	// sort hash array by ascending order
 	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i], hashes[j]) == -1 })
 
-	tree := buildMerkleTree(hashes, func(left, right []byte) []byte {
-		data := append(left, right...)
-		hash := sha3.Sum512(data)
-		return hash[:]
-	})
+	tree := buildMerkleTree(hashes, combineHashes)
 
 	if len(tree) == 0 {
 		return errors.New("could not build merkle tree")
@@ -187,3 +191,19 @@ func (r *RegistrationParams) Sign(registrant account.Account) error {
 
 	return nil
 }
+
+// AssetID computes the asset ID of the registration, which only depends on the fingerprint
+func (r *RegistrationParams) AssetID() string {
+	return ComputeAssetID(r.Fingerprint)
+}
+
+// ComputeAssetID returns the asset ID for a fingerprint, the SHA3-512 digest of the fingerprint string
+func ComputeAssetID(fingerprint string) string {
+	digest := sha3.Sum512([]byte(fingerprint))
+	return hex.EncodeToString(digest[:])
+}
+
+// Verify checks the signature of the registration against the registrant
+func (r *RegistrationParams) Verify() error {
+	return utils.VerifySignature(r, r.Registrant, r.Signature)
+}
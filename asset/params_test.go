@@ -14,6 +14,7 @@ import (
 
 	sdk "github.com/bitmark-inc/bitmark-sdk-go"
 	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
 )
 
 var (
@@ -145,3 +146,26 @@ func TestSign(t *testing.T) {
 
 	assert.Error(t, params.Sign(nil), ErrNullRegistrant)
 }
+
+func TestVerify(t *testing.T) {
+	params, err := NewRegistrationParams("name", map[string]string{"k1": "v1"})
+	assert.NoError(t, err)
+	assert.NoError(t, params.SetFingerprintFromData([]byte("hello world")))
+	assert.EqualError(t, params.Verify(), utils.ErrMissingSignature.Error())
+
+	assert.NoError(t, params.Sign(registrant))
+	assert.NoError(t, params.Verify())
+
+	params.Metadata = "k1\u0000v2"
+	assert.EqualError(t, params.Verify(), utils.ErrInvalidSignature.Error())
+}
+
+func TestAssetID(t *testing.T) {
+	// from the asset record test of bitmarkd
+	assert.Equal(t, "59d06155d25dffdb982729de8dce9d7855ca094d8bab8124b347c40668477056b3c27ccb7d71b54043d207ccd187642bf9c8466f9a8d0dbefb4c41633a7e39ef", ComputeAssetID("0123456789abcdef"))
+
+	params, err := NewRegistrationParams("name", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, params.SetFingerprintFromData([]byte("hello world")))
+	assert.Equal(t, ComputeAssetID(params.Fingerprint), params.AssetID())
+}
@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// PerceptualHashAlgorithm is also the metadata key the hash is stored under
+type PerceptualHashAlgorithm string
+
+const (
+	// DHash compares the brightness of neighbouring pixels, fast and robust to re-encoding
+	DHash = PerceptualHashAlgorithm("dhash")
+	// PHash keeps the low frequencies of a DCT, more robust to contrast and gamma changes
+	PHash = PerceptualHashAlgorithm("phash")
+)
+
+var (
+	ErrUnknownHashAlgorithm   = errors.New("unknown perceptual hash algorithm")
+	ErrInvalidPerceptualHash  = errors.New("invalid perceptual hash")
+	ErrPerceptualHashNotFound = errors.New("metadata has no perceptual hash")
+)
+
+// PerceptualHash is a 64 bit image hash, similar images have hashes a small
+// Hamming distance apart
+type PerceptualHash uint64
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Distance is the number of bits which differ between the hashes, 0 to 64
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func ParsePerceptualHash(s string) (PerceptualHash, error) {
+	if len(s) != 16 {
+		return 0, ErrInvalidPerceptualHash
+	}
+	u, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidPerceptualHash
+	}
+	return PerceptualHash(u), nil
+}
+
+// ComputePerceptualHash hashes a decoded image
+func ComputePerceptualHash(img image.Image, algorithm PerceptualHashAlgorithm) (PerceptualHash, error) {
+	switch algorithm {
+	case DHash:
+		return dHash(img), nil
+	case PHash:
+		return pHash(img), nil
+	}
+	return 0, ErrUnknownHashAlgorithm
+}
+
+// PerceptualHashFromReader decodes a PNG, JPEG or GIF image and hashes it
+func PerceptualHashFromReader(reader io.Reader, algorithm PerceptualHashAlgorithm) (PerceptualHash, error) {
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return 0, err
+	}
+	return ComputePerceptualHash(img, algorithm)
+}
+
+func PerceptualHashFromFile(name string, algorithm PerceptualHashAlgorithm) (PerceptualHash, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return PerceptualHashFromReader(f, algorithm)
+}
+
+// PerceptualHash stores the hash in the metadata under the algorithm name
+func (b *MetadataBuilder) PerceptualHash(algorithm PerceptualHashAlgorithm, h PerceptualHash) *MetadataBuilder {
+	if algorithm != DHash && algorithm != PHash {
+		b.fail(ErrUnknownHashAlgorithm)
+		return b
+	}
+	return b.set(string(algorithm), h.String())
+}
+
+// PerceptualHashFromMetadata reads a hash stored by MetadataBuilder.PerceptualHash
+func PerceptualHashFromMetadata(metadata map[string]string, algorithm PerceptualHashAlgorithm) (PerceptualHash, error) {
+	s, ok := metadata[string(algorithm)]
+	if !ok {
+		return 0, ErrPerceptualHashNotFound
+	}
+	return ParsePerceptualHash(s)
+}
+
+// dHash sets a bit for each pixel of a 9x8 thumbnail brighter than its right neighbour
+func dHash(img image.Image) PerceptualHash {
+	pixels := grayscale(img, 9, 8)
+
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				h |= 1
+			}
+		}
+	}
+	return PerceptualHash(h)
+}
+
+// pHash sets a bit for each of the 8x8 lowest DCT frequencies of a 32x32
+// thumbnail above the median of the frequencies, the DC term excluded
+func pHash(img image.Image) PerceptualHash {
+	const size = 32
+	pixels := grayscale(img, size, size)
+
+	var cosines [8][size]float64
+	for u := 0; u < 8; u++ {
+		for x := 0; x < size; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+
+	// separable DCT: rows first, then columns
+	var rows [size][8]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < size; x++ {
+				sum += pixels[y*size+x] * cosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	coefficients := make([]float64, 64)
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * cosines[v][y]
+			}
+			coefficients[v*8+u] = sum
+		}
+	}
+
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var h uint64
+	for _, c := range coefficients {
+		h <<= 1
+		if c > median {
+			h |= 1
+		}
+	}
+	return PerceptualHash(h)
+}
+
+// grayscale shrinks the image to width x height by averaging the luminance of
+// the pixels covered by each cell
+func grayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	pixels := make([]float64, width*height)
+	if w == 0 || h == 0 {
+		return pixels
+	}
+
+	for cy := 0; cy < height; cy++ {
+		y0 := bounds.Min.Y + cy*h/height
+		y1 := bounds.Min.Y + (cy+1)*h/height
+		if y1 == y0 {
+			y1++
+		}
+		for cx := 0; cx < width; cx++ {
+			x0 := bounds.Min.X + cx*w/width
+			x1 := bounds.Min.X + (cx+1)*w/width
+			if x1 == x0 {
+				x1++
+			}
+
+			sum := 0.0
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+				}
+			}
+			pixels[cy*width+cx] = sum / float64((y1-y0)*(x1-x0)) / 0xffff
+		}
+	}
+	return pixels
+}
+
+// SimilarityMatch is an indexed item close to the queried hash
+type SimilarityMatch struct {
+	ID       string
+	Hash     PerceptualHash
+	Distance int
+}
+
+// SimilarityIndex finds images which are likely duplicates of each other,
+// it is safe for concurrent use
+type SimilarityIndex struct {
+	threshold int
+
+	lock  sync.RWMutex
+	items map[string]PerceptualHash
+}
+
+// NewSimilarityIndex returns an index reporting hashes at most threshold bits
+// apart, around 10 suits both algorithms
+func NewSimilarityIndex(threshold int) *SimilarityIndex {
+	return &SimilarityIndex{
+		threshold: threshold,
+		items:     make(map[string]PerceptualHash),
+	}
+}
+
+// Add indexes a hash, replacing any previous hash of the id
+func (s *SimilarityIndex) Add(id string, h PerceptualHash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items[id] = h
+}
+
+// AddAsset indexes a registered asset by the hash stored in its metadata
+func (s *SimilarityIndex) AddAsset(a *Asset, algorithm PerceptualHashAlgorithm) error {
+	if a == nil {
+		return ErrNullAsset
+	}
+	h, err := PerceptualHashFromMetadata(a.Metadata, algorithm)
+	if err != nil {
+		return err
+	}
+	s.Add(a.ID, h)
+	return nil
+}
+
+func (s *SimilarityIndex) Remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.items, id)
+}
+
+func (s *SimilarityIndex) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.items)
+}
+
+// Candidates returns the indexed items within the threshold of h, closest first
+func (s *SimilarityIndex) Candidates(h PerceptualHash) []SimilarityMatch {
+	s.lock.RLock()
+	matches := make([]SimilarityMatch, 0)
+	for id, other := range s.items {
+		if d := h.Distance(other); d <= s.threshold {
+			matches = append(matches, SimilarityMatch{ID: id, Hash: other, Distance: d})
+		}
+	}
+	s.lock.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
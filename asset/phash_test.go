@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestImage draws a smooth pattern, different for each seed
+func newTestImage(width, height int, seed float64) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx := float64(x) / float64(width)
+			fy := float64(y) / float64(height)
+			v := 0.5 + 0.25*math.Sin(seed*fx*7+fy*3) + 0.25*math.Cos(fy*seed*5-fx*2)
+			img.Set(x, y, color.RGBA{uint8(v * 255), uint8(v * 200), uint8(255 - v*255), 0xff})
+		}
+	}
+	return img
+}
+
+func encodeTestImages(t *testing.T, img image.Image) map[string][]byte {
+	var p, j, g bytes.Buffer
+	assert.NoError(t, png.Encode(&p, img))
+	assert.NoError(t, jpeg.Encode(&j, img, &jpeg.Options{Quality: 40}))
+	assert.NoError(t, gif.Encode(&g, img, nil))
+	return map[string][]byte{"png": p.Bytes(), "jpeg": j.Bytes(), "gif": g.Bytes()}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	original := newTestImage(160, 120, 1)
+	resized := newTestImage(400, 300, 1)
+	other := newTestImage(160, 120, 3)
+
+	for _, algorithm := range []PerceptualHashAlgorithm{DHash, PHash} {
+		h, err := ComputePerceptualHash(original, algorithm)
+		assert.NoError(t, err)
+
+		for format, data := range encodeTestImages(t, original) {
+			decoded, err := PerceptualHashFromReader(bytes.NewReader(data), algorithm)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, h.Distance(decoded), 10, string(algorithm)+" "+format)
+		}
+
+		r, _ := ComputePerceptualHash(resized, algorithm)
+		assert.LessOrEqual(t, h.Distance(r), 10, string(algorithm)+" resized")
+
+		o, _ := ComputePerceptualHash(other, algorithm)
+		assert.Greater(t, h.Distance(o), 16, string(algorithm)+" other")
+	}
+
+	_, err := ComputePerceptualHash(original, PerceptualHashAlgorithm("ahash"))
+	assert.Equal(t, ErrUnknownHashAlgorithm, err)
+
+	_, err = PerceptualHashFromReader(bytes.NewReader([]byte("not an image")), DHash)
+	assert.Equal(t, image.ErrFormat, err)
+}
+
+func TestPerceptualHashString(t *testing.T) {
+	h := PerceptualHash(0x00f0000000000001)
+	assert.Equal(t, "00f0000000000001", h.String())
+
+	parsed, err := ParsePerceptualHash(h.String())
+	assert.NoError(t, err)
+	assert.Equal(t, h, parsed)
+	assert.Equal(t, 5, h.Distance(0))
+
+	for _, s := range []string{"", "00f000000000001", "00f000000000000g"} {
+		_, err := ParsePerceptualHash(s)
+		assert.Equal(t, ErrInvalidPerceptualHash, err)
+	}
+}
+
+func TestPerceptualHashMetadata(t *testing.T) {
+	h := PerceptualHash(0x0123456789abcdef)
+	compact, err := NewMetadataBuilder().PerceptualHash(DHash, h).Source("camera").Compact()
+	assert.NoError(t, err)
+	assert.Equal(t, "dhash\u00000123456789abcdef\u0000source\u0000camera", compact)
+
+	metadata, _ := ParseMetadata(compact)
+	stored, err := PerceptualHashFromMetadata(metadata, DHash)
+	assert.NoError(t, err)
+	assert.Equal(t, h, stored)
+
+	_, err = PerceptualHashFromMetadata(metadata, PHash)
+	assert.Equal(t, ErrPerceptualHashNotFound, err)
+
+	_, err = NewMetadataBuilder().Custom("dhash", h.String()).Build()
+	assert.Error(t, err)
+}
+
+func TestSimilarityIndex(t *testing.T) {
+	index := NewSimilarityIndex(4)
+	index.Add("a", 0x0f)
+	index.Add("b", 0x0e)
+	index.Add("c", 0xff)
+	index.Add("d", 0xf0)
+	assert.Equal(t, 4, index.Len())
+
+	assert.Equal(t, []SimilarityMatch{
+		{ID: "a", Hash: 0x0f, Distance: 0},
+		{ID: "b", Hash: 0x0e, Distance: 1},
+		{ID: "c", Hash: 0xff, Distance: 4},
+	}, index.Candidates(0x0f))
+
+	index.Remove("a")
+	assert.Equal(t, []SimilarityMatch{{ID: "b", Hash: 0x0e, Distance: 1}, {ID: "c", Hash: 0xff, Distance: 4}}, index.Candidates(0x0f))
+	assert.Empty(t, index.Candidates(0xffff000000000000))
+
+	assert.NoError(t, index.AddAsset(&Asset{ID: "e", Metadata: map[string]string{"phash": "000000000000000f"}}, PHash))
+	assert.Equal(t, "e", index.Candidates(0x0f)[0].ID)
+	assert.Equal(t, ErrPerceptualHashNotFound, index.AddAsset(&Asset{ID: "f"}, PHash))
+	assert.Equal(t, ErrNullAsset, index.AddAsset(nil, PHash))
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const MetadataCID = "cid"
+
+var (
+	ErrNotStored       = errors.New("asset content not stored")
+	ErrContentMismatch = errors.New("content does not match the fingerprint")
+	ErrCIDMismatch     = errors.New("stored content does not match the CID in the asset metadata")
+)
+
+// StorageInfo describes content kept in a Storage
+type StorageInfo struct {
+	Fingerprint string `json:"fingerprint"`
+	Size        int64  `json:"size"`
+	CID         string `json:"cid"`
+}
+
+// Storage keeps asset content addressed by its fingerprint. Content with a user
+// defined fingerprint cannot be checked and is not accepted.
+type Storage interface {
+	// Put stores the content, replacing any content stored for the fingerprint
+	Put(fingerprint string, content io.Reader) (*StorageInfo, error)
+	// Get returns ErrNotStored if there is no content for the fingerprint
+	Get(fingerprint string) (io.ReadCloser, error)
+	// Stat returns ErrNotStored if there is no content for the fingerprint
+	Stat(fingerprint string) (*StorageInfo, error)
+}
+
+// FileStorage is a Storage in a local directory; files are named by the asset
+// ID of their fingerprint and are checked against it when stored
+type FileStorage struct {
+	root string
+}
+
+func NewFileStorage(root string) (*FileStorage, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStorage{root: root}, nil
+}
+
+func (s *FileStorage) path(fingerprint string) string {
+	id := ComputeAssetID(fingerprint)
+	return filepath.Join(s.root, id[:2], id)
+}
+
+func (s *FileStorage) Put(fingerprint string, content io.Reader) (*StorageInfo, error) {
+	if strings.HasPrefix(fingerprint, "00") {
+		return nil, ErrUnverifiableFingerprint
+	}
+
+	name := s.path(fingerprint)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".put-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := newCIDHasher()
+	size, err := io.Copy(io.MultiWriter(tmp, h), content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := VerifyContent(fingerprint, FileContent(tmp.Name()))
+	if err != nil {
+		return nil, err
+	}
+	if !report.Match {
+		return nil, ErrContentMismatch
+	}
+
+	info := &StorageInfo{
+		Fingerprint: fingerprint,
+		Size:        size,
+		CID:         h.CID(),
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	// the content goes in first so there is never stat data without content
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(name+".json", data, 0644); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (s *FileStorage) Get(fingerprint string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(fingerprint))
+	if os.IsNotExist(err) {
+		return nil, ErrNotStored
+	}
+	return f, err
+}
+
+func (s *FileStorage) Stat(fingerprint string) (*StorageInfo, error) {
+	name := s.path(fingerprint)
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return nil, ErrNotStored
+	}
+
+	data, err := ioutil.ReadFile(name + ".json")
+	if os.IsNotExist(err) {
+		return nil, ErrNotStored
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info StorageInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// CID records the content identifier of the asset content in the metadata
+func (b *MetadataBuilder) CID(cid string) *MetadataBuilder {
+	return b.set(MetadataCID, cid)
+}
+
+// StoreFile sets the fingerprint from the file, puts the file in storage and
+// records its CID in the metadata. It must be called before Sign; the params are
+// only changed when the file has been stored.
+func (r *RegistrationParams) StoreFile(storage Storage, name string, opts *FingerprintOptions) (*StorageInfo, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	metadata, err := ParseMetadata(r.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprinted := &RegistrationParams{}
+	if err := fingerprinted.SetFingerprintFromFileContext(context.Background(), name, opts); err != nil {
+		return nil, err
+	}
+
+	info, err := storage.Put(fingerprinted.Fingerprint, f)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata[MetadataCID] = info.CID
+	compact := compactMetadata(metadata)
+	if metadataBudget(compact).Remaining < 0 {
+		return nil, ErrInvalidMetadataLength
+	}
+	r.Fingerprint = fingerprinted.Fingerprint
+	r.Metadata = compact
+
+	return info, nil
+}
+
+// Fetch returns the stored content of a registered asset, see FetchAsset
+func Fetch(storage Storage, assetID string) (io.ReadCloser, error) {
+	a, err := Get(assetID)
+	if err != nil {
+		return nil, err
+	}
+	return FetchAsset(storage, a)
+}
+
+// FetchAsset returns the stored content of the asset once it has been checked
+// against the asset fingerprint, and against the CID if one is in the metadata.
+// The content is read twice, once to check it and once by the caller. Assets
+// with a user defined fingerprint are never stored, see Storage.
+func FetchAsset(storage Storage, a *Asset) (io.ReadCloser, error) {
+	if a == nil {
+		return nil, ErrNullAsset
+	}
+
+	if cid, ok := a.Metadata[MetadataCID]; ok {
+		info, err := storage.Stat(a.Fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if info.CID != cid {
+			return nil, ErrCIDMismatch
+		}
+	}
+
+	content := &Content{
+		open: func() (io.ReadCloser, error) {
+			return storage.Get(a.Fingerprint)
+		},
+	}
+	report, err := VerifyAssetContent(a, content)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Match {
+		return nil, ErrContentMismatch
+	}
+
+	return storage.Get(a.Fingerprint)
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+)
+
+func TestComputeCID(t *testing.T) {
+	assert.Equal(t, "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku", CIDFromData(nil))
+
+	cid, err := ComputeCID(strings.NewReader("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, CIDFromData([]byte("hello")), cid)
+	assert.True(t, strings.HasPrefix(cid, "bafkrei"))
+	assert.NotEqual(t, CIDFromData(nil), cid)
+
+	// one chunk is a raw block, more is a dag-pb root
+	chunk := bytes.Repeat([]byte{'a'}, CIDChunkSize)
+	cid, err = ComputeCID(bytes.NewReader(chunk))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cid, "bafkrei"))
+
+	cid, err = ComputeCID(bytes.NewReader(append(chunk, 'a')))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cid, "bafybei"))
+	assert.NotEqual(t, CIDFromData(append(chunk, 'b')), cid)
+}
+
+func TestCIDBalancedLayout(t *testing.T) {
+	leaves := func(n int) []cidNode {
+		nodes := make([]cidNode, n)
+		for i := range nodes {
+			nodes[i] = cidNode{cid: binaryCID(codecRaw, make([]byte, 32)), size: CIDChunkSize, tsize: CIDChunkSize}
+		}
+		return nodes
+	}
+
+	// two leaves under one node
+	root := fileNode(leaves(2))
+	assert.Equal(t, uint64(2*CIDChunkSize), root.size)
+	h := &cidHasher{leaves: leaves(2)}
+	assert.Equal(t, encodeCID(root.cid), h.CID())
+
+	// a full node is wrapped with the next leaf one level up
+	full := fileNode(leaves(cidMaxLinks))
+	root = fileNode([]cidNode{full, fileNode(leaves(1))})
+	h = &cidHasher{leaves: leaves(cidMaxLinks + 1)}
+	assert.Equal(t, encodeCID(root.cid), h.CID())
+	assert.Equal(t, uint64((cidMaxLinks+1)*CIDChunkSize), root.size)
+}
+
+func newTestStorage(t *testing.T) (*FileStorage, func()) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	storage, err := NewFileStorage(filepath.Join(dir, "store"))
+	assert.NoError(t, err)
+	return storage, func() { os.RemoveAll(dir) }
+}
+
+func TestFileStorage(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+
+	content := []byte("asset content")
+	r := &RegistrationParams{}
+	assert.NoError(t, r.SetFingerprintFromData(content))
+
+	_, err := storage.Get(r.Fingerprint)
+	assert.Equal(t, ErrNotStored, err)
+	_, err = storage.Stat(r.Fingerprint)
+	assert.Equal(t, ErrNotStored, err)
+
+	info, err := storage.Put(r.Fingerprint, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, &StorageInfo{Fingerprint: r.Fingerprint, Size: int64(len(content)), CID: CIDFromData(content)}, info)
+
+	stat, err := storage.Stat(r.Fingerprint)
+	assert.NoError(t, err)
+	assert.Equal(t, info, stat)
+
+	reader, err := storage.Get(r.Fingerprint)
+	assert.NoError(t, err)
+	stored, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, content, stored)
+
+	_, err = storage.Put(r.Fingerprint, strings.NewReader("other content"))
+	assert.Equal(t, ErrContentMismatch, err)
+
+	// user defined fingerprints cannot be checked so are not stored
+	_, err = storage.Put("00user defined", strings.NewReader("anything"))
+	assert.Equal(t, ErrUnverifiableFingerprint, err)
+
+	// content over a chunk has the CID of its DAG
+	large := bytes.Repeat([]byte{'b'}, 3*CIDChunkSize)
+	assert.NoError(t, r.SetFingerprintFromData(large))
+	info, err = storage.Put(r.Fingerprint, bytes.NewReader(large))
+	assert.NoError(t, err)
+	assert.Equal(t, CIDFromData(large), info.CID)
+
+	// content without its stat data is not stored
+	assert.NoError(t, os.Remove(storage.path(r.Fingerprint)+".json"))
+	_, err = storage.Stat(r.Fingerprint)
+	assert.Equal(t, ErrNotStored, err)
+}
+
+func TestFileStoragePutRenameFailure(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+
+	content := []byte("asset content")
+	r := &RegistrationParams{}
+	assert.NoError(t, r.SetFingerprintFromData(content))
+
+	// a directory in the way of the content file makes the rename fail
+	name := storage.path(r.Fingerprint)
+	assert.NoError(t, os.MkdirAll(filepath.Join(name, "blocker"), 0755))
+
+	_, err := storage.Put(r.Fingerprint, bytes.NewReader(content))
+	assert.Error(t, err)
+	_, err = os.Stat(name + ".json")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStoreFile(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+
+	name := filepath.Join(storage.root, "..", "file.txt")
+	assert.NoError(t, ioutil.WriteFile(name, []byte("file content"), 0644))
+
+	r, err := NewRegistrationParams("file", map[string]string{"source": "disk"})
+	assert.NoError(t, err)
+	info, err := r.StoreFile(storage, name, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "cid\u0000"+info.CID+"\u0000source\u0000disk", r.Metadata)
+	assert.NoError(t, r.Sign(registrant))
+
+	metadata, _ := ParseMetadata(r.Metadata)
+	a := &Asset{ID: r.AssetID(), Fingerprint: r.Fingerprint, Metadata: metadata}
+	reader, err := FetchAsset(storage, a)
+	assert.NoError(t, err)
+	stored, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, "file content", string(stored))
+
+	// content altered in the store
+	assert.NoError(t, ioutil.WriteFile(storage.path(r.Fingerprint), []byte("file c0ntent"), 0644))
+	_, err = FetchAsset(storage, &Asset{ID: a.ID, Fingerprint: a.Fingerprint})
+	assert.Equal(t, ErrContentMismatch, err)
+
+	a.Metadata[MetadataCID] = CIDFromData(nil)
+	_, err = FetchAsset(storage, a)
+	assert.Equal(t, ErrCIDMismatch, err)
+
+	_, err = FetchAsset(storage, &Asset{ID: a.ID, Fingerprint: "01" + strings.Repeat("0", 128)})
+	assert.Equal(t, ErrNotStored, err)
+}
+
+func TestStoreFileFailureLeavesParams(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+
+	r, err := NewRegistrationParams("file", map[string]string{"source": "disk"})
+	assert.NoError(t, err)
+	before := *r
+
+	small := filepath.Join(storage.root, "..", "small.txt")
+	assert.NoError(t, ioutil.WriteFile(small, []byte("small"), 0644))
+	_, err = r.StoreFile(failingStorage{}, small, nil)
+	assert.Equal(t, ErrNotStored, err)
+	assert.Equal(t, before, *r)
+}
+
+type failingStorage struct{}
+
+func (failingStorage) Put(string, io.Reader) (*StorageInfo, error) { return nil, ErrNotStored }
+func (failingStorage) Get(string) (io.ReadCloser, error)           { return nil, ErrNotStored }
+func (failingStorage) Stat(string) (*StorageInfo, error)           { return nil, ErrNotStored }
+
+func TestFetch(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+
+	r := &RegistrationParams{}
+	assert.NoError(t, r.SetFingerprintFromData([]byte("content")))
+	_, err := storage.Put(r.Fingerprint, strings.NewReader("content"))
+	assert.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v3/assets/"+r.AssetID(), req.URL.Path)
+		fmt.Fprintf(w, `{"asset":{"id":%q,"fingerprint":%q,"metadata":{}}}`, r.AssetID(), r.Fingerprint)
+	}))
+	defer ts.Close()
+
+	sdk.Init(&sdk.Config{
+		HTTPClient: ts.Client(),
+		Network:    sdk.Testnet,
+	})
+	sdk.GetAPIClient().URLAuthority = ts.URL
+
+	reader, err := Fetch(storage, r.AssetID())
+	assert.NoError(t, err)
+	stored, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, "content", string(stored))
+}
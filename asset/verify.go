@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrUnknownFingerprintType  = errors.New("unknown fingerprint type")
+	ErrUnverifiableFingerprint = errors.New("user defined fingerprints cannot be recomputed from content")
+	ErrNullAsset               = errors.New("asset is null")
+)
+
+var fingerprintTypeNames = map[int]string{
+	fingerprintTypeUserDefined:       "user defined",
+	fingerprintTypeSHA3512:           "sha3-512",
+	fingerprintTypeMerkleTree:        "merkle",
+	fingerprintTypeChunkedMerkleTree: "chunked merkle",
+}
+
+// Content is the data checked against a registered fingerprint, either a single
+// stream or a set of files
+type Content struct {
+	open  func() (io.ReadCloser, error)
+	data  [][]byte
+	dir   string
+	opts  *TreeOptions
+	multi bool
+}
+
+// FileContent is the content of a single file
+func FileContent(name string) *Content {
+	return &Content{
+		open: func() (io.ReadCloser, error) {
+			return os.Open(name)
+		},
+	}
+}
+
+// ReaderContent is the content of a reader, it can only be verified once
+func ReaderContent(reader io.Reader) *Content {
+	return &Content{
+		open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(reader), nil
+		},
+	}
+}
+
+// DataContent is a single item for one slice, otherwise a set of files as
+// fingerprinted by SetFingerprintFromDataArray
+func DataContent(data ...[]byte) *Content {
+	if len(data) == 1 {
+		return &Content{
+			open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(data[0])), nil
+			},
+		}
+	}
+	return &Content{data: data, multi: true}
+}
+
+// DirContent is the set of files under root as fingerprinted by SetFingerprintFromDir
+func DirContent(root string, opts *TreeOptions) *Content {
+	return &Content{dir: root, opts: opts, multi: true}
+}
+
+// ContentReport is the outcome of checking content against a fingerprint
+type ContentReport struct {
+	Match    bool   `json:"match"`
+	Type     string `json:"type"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Files    int    `json:"files"` // files hashed, 1 for single stream content
+	Size     int64  `json:"size"`  // bytes hashed
+	Reason   string `json:"reason,omitempty"`
+}
+
+// VerifyAssetContent checks content against the fingerprint of a registered asset;
+// the asset ID must also be the one derived from the fingerprint
+func VerifyAssetContent(a *Asset, content *Content) (*ContentReport, error) {
+	if a == nil {
+		return nil, ErrNullAsset
+	}
+
+	report, err := VerifyContent(a.Fingerprint, content)
+	if err != nil {
+		return nil, err
+	}
+	if a.ID != ComputeAssetID(a.Fingerprint) {
+		report.Match = false
+		report.Reason = "asset ID does not match the fingerprint"
+	}
+	return report, nil
+}
+
+// VerifyContent recomputes the fingerprint of content the way the fingerprint
+// given was made, which is told by its two digit type prefix
+func VerifyContent(fingerprint string, content *Content) (*ContentReport, error) {
+	if content.empty() {
+		return nil, ErrEmptyContent
+	}
+	if len(fingerprint) < 2 {
+		return nil, ErrUnknownFingerprintType
+	}
+	kind, err := strconv.Atoi(fingerprint[:2])
+	if err != nil {
+		return nil, ErrUnknownFingerprintType
+	}
+
+	report := &ContentReport{Expected: fingerprint, Type: fingerprintTypeNames[kind]}
+	if kind == fingerprintTypeUserDefined {
+		return nil, ErrUnverifiableFingerprint
+	}
+	if report.Type == "" {
+		return nil, ErrUnknownFingerprintType
+	}
+	if content.multi && kind != fingerprintTypeMerkleTree {
+		report.Files = content.count()
+		report.Reason = fmt.Sprintf("content has %d files, the fingerprint is of a single file", report.Files)
+		return report, nil
+	}
+
+	switch kind {
+	case fingerprintTypeSHA3512:
+		err = content.stream(report, nil)
+	case fingerprintTypeMerkleTree:
+		err = content.tree(report)
+	case fingerprintTypeChunkedMerkleTree:
+		parts := strings.SplitN(fingerprint[2:], ":", 2)
+		chunkSize, convErr := strconv.Atoi(parts[0])
+		if len(parts) != 2 || convErr != nil || chunkSize <= 0 {
+			return nil, ErrUnknownFingerprintType
+		}
+		err = content.stream(report, &FingerprintOptions{Chunked: true, ChunkSize: chunkSize})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Match = report.Actual == report.Expected
+	if !report.Match {
+		report.Reason = "content does not match the fingerprint"
+	}
+	return report, nil
+}
+
+// empty reports whether there is nothing to hash, a nil content included
+func (c *Content) empty() bool {
+	return c == nil || (c.open == nil && len(c.data) == 0 && c.dir == "")
+}
+
+func (c *Content) count() int {
+	if c.data != nil {
+		return len(c.data)
+	}
+	m, err := (&RegistrationParams{}).SetFingerprintFromDir(c.dir, c.opts)
+	if err != nil {
+		return 0
+	}
+	return len(m.Files)
+}
+
+func (c *Content) stream(report *ContentReport, opts *FingerprintOptions) error {
+	if c.open == nil {
+		return ErrEmptyContent
+	}
+	reader, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if opts == nil {
+		opts = &FingerprintOptions{}
+	}
+	opts.Progress = func(hashed, total int64) { report.Size = hashed }
+
+	r := &RegistrationParams{}
+	if err := r.SetFingerprintFromReaderContext(context.Background(), reader, opts); err != nil {
+		return err
+	}
+	report.Files = 1
+	report.Actual = r.Fingerprint
+	return nil
+}
+
+// tree computes the multi-file fingerprint, a single stream is a tree of one file
+func (c *Content) tree(report *ContentReport) error {
+	r := &RegistrationParams{}
+	switch {
+	case c.dir != "":
+		m, err := r.SetFingerprintFromDir(c.dir, c.opts)
+		if err != nil {
+			return err
+		}
+		report.Files = len(m.Files)
+		for _, f := range m.Files {
+			report.Size += f.Size
+		}
+	case c.data != nil:
+		if err := r.SetFingerprintFromDataArray(c.data); err != nil {
+			return err
+		}
+		report.Files = len(c.data)
+		for _, d := range c.data {
+			report.Size += int64(len(d))
+		}
+	case c.open != nil:
+		reader, err := c.open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		h := sha3.New512()
+		size, err := io.Copy(h, reader)
+		if err != nil {
+			return err
+		}
+		if err := r.setFingerprintFromHashes([][]byte{h.Sum(nil)}); err != nil {
+			return err
+		}
+		report.Files = 1
+		report.Size = size
+	default:
+		return ErrEmptyContent
+	}
+	report.Actual = r.Fingerprint
+	return nil
+}
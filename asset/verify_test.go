@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package asset
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyContentSHA3(t *testing.T) {
+	fingerprint := "01840006653e9ac9e95117a15c915caab81662918e925de9e004f774ff82d7079a40d4d27b1b372657c61d46d470304c88c788b3a4527ad074d1dccbee5dbaa99a"
+
+	f, err := ioutil.TempFile("", "content")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("hello world")
+	f.Close()
+
+	for _, content := range []*Content{
+		FileContent(f.Name()),
+		ReaderContent(bytes.NewReader([]byte("hello world"))),
+		DataContent([]byte("hello world")),
+	} {
+		report, err := VerifyContent(fingerprint, content)
+		assert.NoError(t, err)
+		assert.True(t, report.Match)
+		assert.Equal(t, "sha3-512", report.Type)
+		assert.Equal(t, int64(11), report.Size)
+		assert.Equal(t, 1, report.Files)
+	}
+
+	report, err := VerifyContent(fingerprint, DataContent([]byte("hello world!")))
+	assert.NoError(t, err)
+	assert.False(t, report.Match)
+	assert.Equal(t, "content does not match the fingerprint", report.Reason)
+	assert.Equal(t, fingerprint, report.Expected)
+	assert.NotEqual(t, fingerprint, report.Actual)
+
+	report, err = VerifyContent(fingerprint, DataContent([]byte("hello"), []byte("world")))
+	assert.NoError(t, err)
+	assert.False(t, report.Match)
+	assert.Equal(t, "content has 2 files, the fingerprint is of a single file", report.Reason)
+
+	_, err = VerifyContent(fingerprint, FileContent(f.Name()+".missing"))
+	assert.Error(t, err)
+}
+
+func TestVerifyContentMerkle(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	params, _ := NewRegistrationParams("", nil)
+	assert.NoError(t, params.SetFingerprintFromDataArray([][]byte{
+		[]byte("hello world"),
+		[]byte("this is a test case"),
+		[]byte("bitmark sdk"),
+	}))
+
+	report, err := VerifyContent(params.Fingerprint, DirContent(dir, nil))
+	assert.NoError(t, err)
+	assert.True(t, report.Match)
+	assert.Equal(t, "merkle", report.Type)
+	assert.Equal(t, 3, report.Files)
+	assert.Equal(t, int64(41), report.Size)
+
+	report, err = VerifyContent(params.Fingerprint, DirContent(dir, &TreeOptions{Exclude: []string{"*.log"}}))
+	assert.NoError(t, err)
+	assert.False(t, report.Match)
+
+	report, err = VerifyContent(params.Fingerprint, DataContent([]byte("bitmark sdk"), []byte("hello world"), []byte("this is a test case")))
+	assert.NoError(t, err)
+	assert.True(t, report.Match)
+
+	// a single file is a tree of one
+	assert.NoError(t, params.SetFingerprintFromDataArray([][]byte{[]byte("hello world")}))
+	report, err = VerifyContent(params.Fingerprint, ReaderContent(bytes.NewReader([]byte("hello world"))))
+	assert.NoError(t, err)
+	assert.True(t, report.Match)
+}
+
+func TestVerifyContentChunked(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	params, _ := NewRegistrationParams("", nil)
+	opts := &FingerprintOptions{Chunked: true, ChunkSize: 999}
+	assert.NoError(t, params.SetFingerprintFromReaderContext(context.Background(), bytes.NewReader(content), opts))
+
+	report, err := VerifyContent(params.Fingerprint, DataContent(content))
+	assert.NoError(t, err)
+	assert.True(t, report.Match)
+	assert.Equal(t, "chunked merkle", report.Type)
+	assert.Equal(t, int64(len(content)), report.Size)
+
+	report, err = VerifyContent(params.Fingerprint, DataContent(content[1:]))
+	assert.NoError(t, err)
+	assert.False(t, report.Match)
+
+	_, err = VerifyContent("03abc", DataContent(content))
+	assert.EqualError(t, err, ErrUnknownFingerprintType.Error())
+}
+
+func TestVerifyContentUnsupported(t *testing.T) {
+	_, err := VerifyContent("00hello world", DataContent([]byte("hello world")))
+	assert.EqualError(t, err, ErrUnverifiableFingerprint.Error())
+
+	for _, fingerprint := range []string{"", "0", "09abc", "xx"} {
+		_, err = VerifyContent(fingerprint, DataContent([]byte("hello world")))
+		assert.EqualError(t, err, ErrUnknownFingerprintType.Error())
+	}
+}
+
+func TestVerifyContentEmpty(t *testing.T) {
+	for _, fingerprint := range []string{"02abcd", "01abcd", "03256:abcd"} {
+		_, err := VerifyContent(fingerprint, DataContent())
+		assert.EqualError(t, err, ErrEmptyContent.Error())
+
+		_, err = VerifyContent(fingerprint, nil)
+		assert.EqualError(t, err, ErrEmptyContent.Error())
+	}
+}
+
+func TestVerifyAssetContent(t *testing.T) {
+	fingerprint := "01840006653e9ac9e95117a15c915caab81662918e925de9e004f774ff82d7079a40d4d27b1b372657c61d46d470304c88c788b3a4527ad074d1dccbee5dbaa99a"
+	a := &Asset{ID: ComputeAssetID(fingerprint), Fingerprint: fingerprint}
+
+	report, err := VerifyAssetContent(a, DataContent([]byte("hello world")))
+	assert.NoError(t, err)
+	assert.True(t, report.Match)
+
+	a.ID = ComputeAssetID("01")
+	report, err = VerifyAssetContent(a, DataContent([]byte("hello world")))
+	assert.NoError(t, err)
+	assert.False(t, report.Match)
+	assert.Equal(t, "asset ID does not match the fingerprint", report.Reason)
+
+	_, err = VerifyAssetContent(nil, DataContent([]byte("hello world")))
+	assert.EqualError(t, err, ErrNullAsset.Error())
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/block"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+// blockTimeSamples is the number of latest blocks the block time is averaged over
+const blockTimeSamples = 100
+
+var ErrInvalidBlockExpiry = errors.New("invalid block expiry: must be at least one block ahead")
+
+// ExpiresInBlocks sets the grant to expire n blocks after the current block
+func (s *ShareGrantingParams) ExpiresInBlocks(n uint64) error {
+	beforeBlock, err := beforeBlockIn(n)
+	if err != nil {
+		return err
+	}
+	s.Grant.BeforeBlock = beforeBlock
+	return nil
+}
+
+// ExpiresAfter sets the grant to expire after about d, going by the recent block time
+func (s *ShareGrantingParams) ExpiresAfter(d time.Duration) error {
+	beforeBlock, err := beforeBlockAfter(d)
+	if err != nil {
+		return err
+	}
+	s.Grant.BeforeBlock = beforeBlock
+	return nil
+}
+
+// ExpiresInBlocks sets the swap to expire n blocks after the current block
+func (p *ShareSwapParams) ExpiresInBlocks(n uint64) error {
+	beforeBlock, err := beforeBlockIn(n)
+	if err != nil {
+		return err
+	}
+	p.Swap.BeforeBlock = beforeBlock
+	return nil
+}
+
+// ExpiresAfter sets the swap to expire after about d, going by the recent block time
+func (p *ShareSwapParams) ExpiresAfter(d time.Duration) error {
+	beforeBlock, err := beforeBlockAfter(d)
+	if err != nil {
+		return err
+	}
+	p.Swap.BeforeBlock = beforeBlock
+	return nil
+}
+
+func beforeBlockIn(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrInvalidBlockExpiry
+	}
+	current, err := block.Current()
+	if err != nil {
+		return 0, err
+	}
+	return current.Number + n, nil
+}
+
+// beforeBlockAfter takes the block time and the current height from one listing
+func beforeBlockAfter(d time.Duration) (uint64, error) {
+	if d <= 0 {
+		return 0, ErrInvalidBlockExpiry
+	}
+	blocks, err := block.List(block.NewQueryParamsBuilder().To(utils.Earlier).Limit(blockTimeSamples))
+	if err != nil {
+		return 0, err
+	}
+	if len(blocks) == 0 {
+		return 0, block.ErrNoBlocks
+	}
+	blockTime := block.AverageInterval(blocks)
+
+	// round up so the request lasts at least d
+	n := uint64((d + blockTime - 1) / blockTime)
+	return blocks[0].Number + n, nil
+}
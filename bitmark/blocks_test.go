@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/block"
+)
+
+// newHeightServer lists blocks at the height given, counting the requests made
+func newHeightServer(t *testing.T, height uint64, interval time.Duration, requests *int32) *httptest.Server {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/blocks", r.URL.Path)
+		atomic.AddInt32(requests, 1)
+		blocks := []*block.Block{
+			{Number: height, CreatedAt: time.Unix(0, 0).Add(time.Duration(height) * interval)},
+			{Number: height - 10, CreatedAt: time.Unix(0, 0).Add(time.Duration(height-10) * interval)},
+		}
+		if r.URL.Query().Get("limit") == "1" {
+			blocks = blocks[:1]
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"blocks": blocks})
+	})
+}
+
+func TestGrantExpiry(t *testing.T) {
+	var requests int32
+	ts := newHeightServer(t, 1000, 2*time.Minute, &requests)
+	defer ts.Close()
+
+	grant := NewShareGrantingParams(testShareID, receiver.AccountNumber(), 10, nil)
+	assert.NoError(t, grant.ExpiresInBlocks(50))
+	assert.Equal(t, uint64(1050), grant.Grant.BeforeBlock)
+
+	// an hour is 30 blocks of two minutes, a part of a block counts as one;
+	// the height comes from the same listing as the block time
+	atomic.StoreInt32(&requests, 0)
+	assert.NoError(t, grant.ExpiresAfter(time.Hour))
+	assert.Equal(t, uint64(1030), grant.Grant.BeforeBlock)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.NoError(t, grant.ExpiresAfter(time.Hour+time.Second))
+	assert.Equal(t, uint64(1031), grant.Grant.BeforeBlock)
+
+	assert.Equal(t, ErrInvalidBlockExpiry, grant.ExpiresInBlocks(0))
+	assert.Equal(t, ErrInvalidBlockExpiry, grant.ExpiresAfter(0))
+}
+
+func TestSwapExpiry(t *testing.T) {
+	var requests int32
+	ts := newHeightServer(t, 2000, 3*time.Minute, &requests)
+	defer ts.Close()
+
+	swap := NewShareSwapParams(0)
+	assert.NoError(t, swap.ExpiresInBlocks(10))
+	assert.Equal(t, uint64(2010), swap.Swap.BeforeBlock)
+	assert.NoError(t, swap.ExpiresAfter(time.Hour))
+	assert.Equal(t, uint64(2020), swap.Swap.BeforeBlock)
+
+	// the signed swap carries the expiry
+	swap.FromShare(testShareID, sender.AccountNumber(), 1).ToShare(testShareID, receiver.AccountNumber(), 2)
+	assert.NoError(t, swap.Sign(sender))
+	assert.NoError(t, swap.Swap.Verify())
+}
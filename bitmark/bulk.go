@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+// maxLookupBatchSize is the most bitmarks a list query returns
+const maxLookupBatchSize = 100
+
+var (
+	ErrBulkTransferIncomplete = errors.New("some bitmarks were not transferred")
+	ErrBitmarkNotFound        = errors.New("bitmark not found")
+	ErrNotBitmarkOwner        = errors.New("sender does not own the bitmark")
+	ErrBitmarkHasOffer        = errors.New("bitmark has a pending transfer offer")
+)
+
+type BulkTransferOptions struct {
+	// Workers is the number of transfers in flight, 1 if 0
+	Workers int
+	// Interval is the least time between two requests, no limit if 0
+	Interval time.Duration
+}
+
+// BulkTransferResult is the outcome of the transfer of one bitmark
+type BulkTransferResult struct {
+	BitmarkID string
+	TxID      string
+	// SentTxID is the tx ID of the last transfer sent, computed before it was
+	// sent, so a transfer accepted without its response being received is
+	// still recognised
+	SentTxID string
+	Err      error
+}
+
+// BulkTransfer moves many bitmarks of the sender to one receiver
+type BulkTransfer struct {
+	Receiver string
+	Results  []*BulkTransferResult
+	opts     BulkTransferOptions
+}
+
+func NewBulkTransfer(receiver string, bitmarkIDs []string, opts *BulkTransferOptions) (*BulkTransfer, error) {
+	if err := account.ValidateAccountNumber(receiver); err != nil {
+		return nil, err
+	}
+
+	b := &BulkTransfer{Receiver: receiver}
+	if opts != nil {
+		b.opts = *opts
+	}
+	if b.opts.Workers <= 0 {
+		b.opts.Workers = 1
+	}
+
+	seen := make(map[string]bool, len(bitmarkIDs))
+	for _, bitmarkID := range bitmarkIDs {
+		if seen[bitmarkID] {
+			continue
+		}
+		seen[bitmarkID] = true
+		b.Results = append(b.Results, &BulkTransferResult{BitmarkID: bitmarkID})
+	}
+	return b, nil
+}
+
+// Submit transfers the bitmarks without a tx ID yet: their head tx IDs are
+// looked up 100 at a time, then each transfer is signed and sent. It returns
+// ErrBulkTransferIncomplete if any failed, Submit can then be called again to
+// resume with the heads looked up afresh; a bitmark whose head is the transfer
+// sent before is then done.
+func (b *BulkTransfer) Submit(ctx context.Context, sender account.Account) error {
+	if sender == nil {
+		return ErrNullOwner
+	}
+
+	limiter := newRateLimiter(b.opts.Interval)
+	defer limiter.stop()
+
+	pending := b.pending()
+	for start := 0; start < len(pending); start += maxLookupBatchSize {
+		end := start + maxLookupBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+		b.lookupHeads(ctx, sender.AccountNumber(), pending[start:end])
+	}
+
+	transfers := make(chan *bulkTransferItem)
+	var wg sync.WaitGroup
+	for w := 0; w < b.opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range transfers {
+				if err := limiter.wait(ctx); err != nil {
+					item.result.Err = err
+					continue
+				}
+				b.transfer(ctx, sender, item)
+			}
+		}()
+	}
+	for _, item := range pending {
+		if item.result.Err == nil && item.result.TxID == "" {
+			transfers <- item
+		}
+	}
+	close(transfers)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(b.Failed()) > 0 {
+		return ErrBulkTransferIncomplete
+	}
+	return nil
+}
+
+type bulkTransferItem struct {
+	result *BulkTransferResult
+	head   string
+}
+
+func (b *BulkTransfer) pending() []*bulkTransferItem {
+	items := make([]*bulkTransferItem, 0)
+	for _, r := range b.Results {
+		if r.TxID == "" {
+			r.Err = nil
+			items = append(items, &bulkTransferItem{result: r})
+		}
+	}
+	return items
+}
+
+func (b *BulkTransfer) lookupHeads(ctx context.Context, owner string, items []*bulkTransferItem) {
+	bitmarkIDs := make([]string, len(items))
+	for i, item := range items {
+		bitmarkIDs[i] = item.result.BitmarkID
+	}
+
+	builder := NewQueryParamsBuilder().BitmarkIDs(bitmarkIDs).Limit(len(bitmarkIDs))
+	bitmarks, err := list(ctx, builder)
+	if err != nil {
+		for _, item := range items {
+			item.result.Err = err
+		}
+		return
+	}
+
+	found := make(map[string]*Bitmark, len(bitmarks))
+	for _, bmk := range bitmarks {
+		found[bmk.ID] = bmk
+	}
+	for _, item := range items {
+		bmk, ok := found[item.result.BitmarkID]
+		switch {
+		case !ok:
+			item.result.Err = ErrBitmarkNotFound
+		case item.result.SentTxID != "" && bmk.LatestTxID == item.result.SentTxID:
+			// sent before, the response was lost
+			item.result.TxID = item.result.SentTxID
+		case bmk.Owner != owner:
+			item.result.Err = ErrNotBitmarkOwner
+		case bmk.Offer != nil:
+			item.result.Err = ErrBitmarkHasOffer
+		default:
+			item.head = bmk.LatestTxID
+		}
+	}
+}
+
+func (b *BulkTransfer) transfer(ctx context.Context, sender account.Account, item *bulkTransferItem) {
+	result := item.result
+
+	params, err := NewTransferParams(b.Receiver)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	params.FromLatestTx(item.head)
+	if err := params.Sign(sender); err != nil {
+		result.Err = err
+		return
+	}
+	sentTxID, err := params.Transfer.TxID()
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.SentTxID = sentTxID
+
+	txID, err := transfer(ctx, params)
+	if err == nil {
+		result.TxID = txID
+		return
+	}
+
+	// the transfer may have been accepted with its response lost
+	builder := NewQueryParamsBuilder().BitmarkIDs([]string{result.BitmarkID}).Limit(1)
+	if bitmarks, listErr := list(ctx, builder); listErr == nil && len(bitmarks) == 1 && bitmarks[0].LatestTxID == sentTxID {
+		result.TxID = sentTxID
+		return
+	}
+	result.Err = err
+}
+
+// TxIDs maps the bitmarks transferred to their transfer tx IDs
+func (b *BulkTransfer) TxIDs() map[string]string {
+	txIDs := make(map[string]string)
+	for _, r := range b.Results {
+		if r.TxID != "" {
+			txIDs[r.BitmarkID] = r.TxID
+		}
+	}
+	return txIDs
+}
+
+// Failed returns the bitmarks not transferred
+func (b *BulkTransfer) Failed() []*BulkTransferResult {
+	failed := make([]*BulkTransferResult, 0)
+	for _, r := range b.Results {
+		if r.TxID == "" {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// rateLimiter spaces requests by at least an interval
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	if interval <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{ticker: time.NewTicker(interval)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-l.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
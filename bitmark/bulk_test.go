@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+)
+
+// bulkHead is a made up head tx ID for a test bitmark
+func bulkHead(bitmarkID string) string {
+	digest := sha3.Sum256([]byte(bitmarkID))
+	return hex.EncodeToString(digest[:])
+}
+
+type bulkServer struct {
+	lock      sync.Mutex
+	lookups   [][]string
+	transfers map[string]int
+	inFlight  int32
+	maxIn     int32
+	owners    map[string]string
+	heads     map[string]string
+	offers    map[string]bool
+	fail      func(link string, attempt int) bool
+	// lose makes the transfer go through but answers with an error
+	lose func(link string, attempt int) bool
+}
+
+func (s *bulkServer) start(t *testing.T) *httptest.Server {
+	s.transfers = make(map[string]int)
+	s.heads = make(map[string]string)
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/bitmarks":
+			ids := r.URL.Query()["bitmark_ids"]
+			s.lock.Lock()
+			s.lookups = append(s.lookups, ids)
+			s.lock.Unlock()
+
+			bitmarks := make([]*Bitmark, 0)
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			for _, id := range ids {
+				owner, ok := s.owners[id]
+				if !ok {
+					continue
+				}
+				head, ok := s.heads[id]
+				if !ok {
+					head = bulkHead(id)
+				}
+				bmk := &Bitmark{ID: id, LatestTxID: head, Owner: owner}
+				if s.offers[id] {
+					bmk.Offer = &TransferOffer{ID: "offer"}
+				}
+				bitmarks = append(bitmarks, bmk)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"bitmarks": bitmarks})
+
+		case "/v3/transfer":
+			n := atomic.AddInt32(&s.inFlight, 1)
+			defer atomic.AddInt32(&s.inFlight, -1)
+
+			var params TransferParams
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			assert.NoError(t, params.Transfer.Verify(sender.AccountNumber()))
+			assert.Equal(t, receiver.AccountNumber(), params.Transfer.Owner)
+
+			s.lock.Lock()
+			if n > s.maxIn {
+				s.maxIn = n
+			}
+			s.transfers[params.Transfer.Link]++
+			attempt := s.transfers[params.Transfer.Link]
+			s.lock.Unlock()
+			time.Sleep(time.Millisecond)
+
+			if s.fail != nil && s.fail(params.Transfer.Link, attempt) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintln(w, `{"code":1000,"message":"invalid parameters"}`)
+				return
+			}
+			txID, err := params.Transfer.TxID()
+			assert.NoError(t, err)
+
+			s.lock.Lock()
+			for id := range s.owners {
+				if bulkHead(id) == params.Transfer.Link {
+					s.owners[id] = params.Transfer.Owner
+					s.heads[id] = txID
+				}
+			}
+			s.lock.Unlock()
+
+			if s.lose != nil && s.lose(params.Transfer.Link, attempt) {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintln(w, `{"code":5000,"message":"internal error"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"txID":%q}`, txID)
+		}
+	})
+}
+
+func bulkBitmarkIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%064x", i+1)
+	}
+	return ids
+}
+
+func TestBulkTransfer(t *testing.T) {
+	ids := bulkBitmarkIDs(150)
+	server := &bulkServer{owners: make(map[string]string)}
+	for _, id := range ids {
+		server.owners[id] = sender.AccountNumber()
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	bulk, err := NewBulkTransfer(receiver.AccountNumber(), append(ids, ids[0]), &BulkTransferOptions{Workers: 4})
+	assert.NoError(t, err)
+	assert.Len(t, bulk.Results, 150)
+
+	assert.NoError(t, bulk.Submit(context.Background(), sender))
+	assert.Len(t, server.lookups, 2)
+	assert.Len(t, server.lookups[0], 100)
+	assert.Len(t, server.lookups[1], 50)
+	assert.LessOrEqual(t, server.maxIn, int32(4))
+
+	txIDs := bulk.TxIDs()
+	assert.Len(t, txIDs, 150)
+	for _, id := range ids {
+		transfer := &TransferRequest{Link: bulkHead(id), Owner: receiver.AccountNumber()}
+		assert.NoError(t, (&TransferParams{Transfer: transfer}).Sign(sender))
+		expected, _ := transfer.TxID()
+		assert.Equal(t, expected, txIDs[id])
+	}
+	assert.Empty(t, bulk.Failed())
+}
+
+func TestBulkTransferResume(t *testing.T) {
+	ids := bulkBitmarkIDs(5)
+	server := &bulkServer{
+		owners: map[string]string{
+			ids[0]: sender.AccountNumber(),
+			ids[1]: sender.AccountNumber(),
+			ids[2]: receiver.AccountNumber(),
+			ids[4]: sender.AccountNumber(),
+		},
+		offers: map[string]bool{ids[4]: true},
+		fail: func(link string, attempt int) bool {
+			return link == bulkHead(ids[1]) && attempt == 1
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	bulk, err := NewBulkTransfer(receiver.AccountNumber(), ids, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ErrBulkTransferIncomplete, bulk.Submit(context.Background(), sender))
+	assert.NotEmpty(t, bulk.Results[0].TxID)
+	assert.IsType(t, &sdk.APIError{}, bulk.Results[1].Err)
+	assert.Equal(t, ErrNotBitmarkOwner, bulk.Results[2].Err)
+	assert.Equal(t, ErrBitmarkNotFound, bulk.Results[3].Err)
+	assert.Equal(t, ErrBitmarkHasOffer, bulk.Results[4].Err)
+	assert.Len(t, bulk.Failed(), 4)
+
+	// resuming sends only what failed
+	server.owners[ids[2]] = sender.AccountNumber()
+	delete(server.offers, ids[4])
+	delete(server.owners, ids[3])
+	txID := bulk.Results[0].TxID
+	lookups := len(server.lookups)
+
+	assert.Equal(t, ErrBulkTransferIncomplete, bulk.Submit(context.Background(), sender))
+	assert.Equal(t, []string{ids[1], ids[2], ids[3], ids[4]}, server.lookups[lookups])
+	assert.Equal(t, txID, bulk.Results[0].TxID)
+	assert.Equal(t, 1, server.transfers[bulkHead(ids[0])])
+	failed := bulk.Failed()
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, ids[3], failed[0].BitmarkID)
+	}
+}
+
+func TestBulkTransferLostResponse(t *testing.T) {
+	ids := bulkBitmarkIDs(2)
+	server := &bulkServer{
+		owners: map[string]string{
+			ids[0]: sender.AccountNumber(),
+			ids[1]: sender.AccountNumber(),
+		},
+		lose: func(link string, attempt int) bool {
+			return attempt == 1
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	// the transfer went through, the new head is the tx ID sent
+	bulk, err := NewBulkTransfer(receiver.AccountNumber(), ids, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, bulk.Submit(context.Background(), sender))
+	for _, r := range bulk.Results {
+		assert.NotEmpty(t, r.SentTxID)
+		assert.Equal(t, r.SentTxID, r.TxID)
+	}
+
+	// a result still failed is done on resume once the head shows the transfer
+	r := bulk.Results[1]
+	r.TxID = ""
+	r.Err = &sdk.APIError{Code: 5000, Message: "internal error"}
+	assert.NoError(t, bulk.Submit(context.Background(), sender))
+	assert.Equal(t, r.SentTxID, r.TxID)
+	assert.Equal(t, 1, server.transfers[bulkHead(ids[1])])
+}
+
+func TestBulkTransferRateLimit(t *testing.T) {
+	ids := bulkBitmarkIDs(4)
+	server := &bulkServer{owners: make(map[string]string)}
+	for _, id := range ids {
+		server.owners[id] = sender.AccountNumber()
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	bulk, _ := NewBulkTransfer(receiver.AccountNumber(), ids, &BulkTransferOptions{Workers: 4, Interval: 20 * time.Millisecond})
+	start := time.Now()
+	assert.NoError(t, bulk.Submit(context.Background(), sender))
+	// one lookup and four transfers
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(100*time.Millisecond))
+}
+
+func TestBulkTransferInvalid(t *testing.T) {
+	_, err := NewBulkTransfer("invalid", nil, nil)
+	assert.Error(t, err)
+
+	bulk, _ := NewBulkTransfer(receiver.AccountNumber(), nil, nil)
+	assert.Equal(t, ErrNullOwner, bulk.Submit(context.Background(), nil))
+}
@@ -6,6 +6,7 @@ package bitmark
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,6 +23,10 @@ type txItem struct {
 }
 
 func Issue(params *IssuanceParams) ([]string, error) {
+	return issue(context.Background(), params)
+}
+
+func issue(ctx context.Context, params *IssuanceParams) ([]string, error) {
 	client := sdk.GetAPIClient()
 
 	body := new(bytes.Buffer)
@@ -39,7 +44,7 @@ func Issue(params *IssuanceParams) ([]string, error) {
 			ID string `json:"id"`
 		} `json:"bitmarks"`
 	}
-	if err := client.Do(req, &result); err != nil {
+	if err := client.Do(req.WithContext(ctx), &result); err != nil {
 		return nil, err
 	}
 
@@ -52,6 +57,10 @@ func Issue(params *IssuanceParams) ([]string, error) {
 }
 
 func Transfer(params *TransferParams) (string, error) {
+	return transfer(context.Background(), params)
+}
+
+func transfer(ctx context.Context, params *TransferParams) (string, error) {
 	client := sdk.GetAPIClient()
 
 	body := new(bytes.Buffer)
@@ -65,7 +74,7 @@ func Transfer(params *TransferParams) (string, error) {
 	}
 
 	var result txItem
-	if err := client.Do(req, &result); err != nil {
+	if err := client.Do(req.WithContext(ctx), &result); err != nil {
 		return "", err
 	}
 
@@ -230,6 +239,15 @@ func GetWithAsset(bitmarkID string) (*Bitmark, *asset.Asset, error) {
 }
 
 func List(builder *QueryParamsBuilder) ([]*Bitmark, []*asset.Asset, error) {
+	return listWithAssets(context.Background(), builder)
+}
+
+func list(ctx context.Context, builder *QueryParamsBuilder) ([]*Bitmark, error) {
+	bitmarks, _, err := listWithAssets(ctx, builder)
+	return bitmarks, err
+}
+
+func listWithAssets(ctx context.Context, builder *QueryParamsBuilder) ([]*Bitmark, []*asset.Asset, error) {
 	params, err := builder.Build()
 
 	if err != nil {
@@ -248,17 +266,128 @@ func List(builder *QueryParamsBuilder) ([]*Bitmark, []*asset.Asset, error) {
 		Assets   []*asset.Asset `json:"assets"`
 	}
 
-	if err := client.Do(req, &result); err != nil {
+	if err := client.Do(req.WithContext(ctx), &result); err != nil {
 		return nil, nil, err
 	}
 
 	return result.Bitmarks, result.Assets, nil
 }
 
+// listAllBitmarks pages through all the bitmarks matched by builder, see pageAll
+func listAllBitmarks(ctx context.Context, builder *QueryParamsBuilder) ([]*Bitmark, error) {
+	bitmarks := make([]*Bitmark, 0)
+	var page []*Bitmark
+	err := pageAll(func(at int) ([]int, error) {
+		var err error
+		page, err = list(ctx, builder.At(at).To(utils.Later).Limit(maxLookupBatchSize))
+		if err != nil {
+			return nil, err
+		}
+		offsets := make([]int, len(page))
+		for i, bmk := range page {
+			offsets[i] = bmk.Offset
+		}
+		return offsets, nil
+	}, func(i int) {
+		bitmarks = append(bitmarks, page[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bitmarks, nil
+}
+
+// pageAll pages through a listing in offset order from the start. listPage
+// lists the page of the items from offset at on and returns their offsets; keep
+// is called with the index of each item not listed before. An empty page, or one
+// with nothing new, is the end.
+func pageAll(listPage func(at int) ([]int, error), keep func(i int)) error {
+	at := 0
+	for {
+		offsets, err := listPage(at)
+		if err != nil {
+			return err
+		}
+
+		next := at
+		for i, offset := range offsets {
+			if offset < at {
+				continue
+			}
+			if offset >= next {
+				next = offset + 1
+			}
+			keep(i)
+		}
+		if next == at {
+			return nil
+		}
+		at = next
+	}
+}
+
+// GetShareBalance returns ErrShareNotFound if the owner holds none of the share
 func GetShareBalance(shareID, owner string) (*Share, error) {
+	vals := url.Values{}
+	vals.Set("share_id", shareID)
+	vals.Set("owner", owner)
+
+	shares, err := listShares(vals)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 {
+		return nil, ErrShareNotFound
+	}
+
+	return shares[0], nil
+}
+
+// ListShareBalances returns the balances of all the shares the owner holds
+func ListShareBalances(owner string) ([]*Share, error) {
+	vals := url.Values{}
+	vals.Set("owner", owner)
+	return listAllShares(vals)
+}
+
+// ListShareHolders returns the balances of all the owners of a share
+func ListShareHolders(shareID string) ([]*Share, error) {
+	vals := url.Values{}
+	vals.Set("share_id", shareID)
+	return listAllShares(vals)
+}
+
+// listAllShares pages through all the balances matched by vals, see pageAll
+func listAllShares(vals url.Values) ([]*Share, error) {
+	shares := make([]*Share, 0)
+	var page []*Share
+	err := pageAll(func(at int) ([]int, error) {
+		vals.Set("at", strconv.Itoa(at))
+		vals.Set("to", string(utils.Later))
+		vals.Set("limit", strconv.Itoa(maxLookupBatchSize))
+		var err error
+		page, err = listShares(vals)
+		if err != nil {
+			return nil, err
+		}
+		offsets := make([]int, len(page))
+		for i, share := range page {
+			offsets[i] = share.Offset
+		}
+		return offsets, nil
+	}, func(i int) {
+		shares = append(shares, page[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func listShares(vals url.Values) ([]*Share, error) {
 	client := sdk.GetAPIClient()
 
-	req, err := client.NewRequest("GET", fmt.Sprintf("/v3/shares?share_id=%s&owner=%s", shareID, owner), nil)
+	req, err := client.NewRequest("GET", "/v3/shares?"+vals.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -269,25 +398,51 @@ func GetShareBalance(shareID, owner string) (*Share, error) {
 	if err := client.Do(req, &result); err != nil {
 		return nil, err
 	}
-	if len(result.Shares) == 0 {
-		return nil, nil
-	}
 
-	return result.Shares[0], nil
+	return result.Shares, nil
 }
 
+// ListShareOffers pages through all the share offers from and to the accounts,
+// either of which may be empty
 func ListShareOffers(from, to string) ([]*ShareOffer, error) {
-	client := sdk.GetAPIClient()
-
-	vals := url.Values{}
-	if from != "" {
-		vals.Set("from", from)
+	offers := make([]*ShareOffer, 0)
+	var page []*ShareOffer
+	err := pageAll(func(at int) ([]int, error) {
+		builder := NewShareOfferQueryBuilder().At(at).Limit(maxLookupBatchSize)
+		if from != "" {
+			builder.OfferFrom(from)
+		}
+		if to != "" {
+			builder.OfferTo(to)
+		}
+		var err error
+		page, err = ListShareOffersWithParams(builder)
+		if err != nil {
+			return nil, err
+		}
+		offsets := make([]int, len(page))
+		for i, offer := range page {
+			offsets[i] = offer.Offset
+		}
+		return offsets, nil
+	}, func(i int) {
+		offers = append(offers, page[i])
+	})
+	if err != nil {
+		return nil, err
 	}
-	if to != "" {
-		vals.Set("to", to)
+	return offers, nil
+}
+
+// ListShareOffersWithParams returns one page of the share offers matched by builder
+func ListShareOffersWithParams(builder *ShareOfferQueryBuilder) ([]*ShareOffer, error) {
+	params, err := builder.Build()
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := client.NewRequest("GET", fmt.Sprintf("/v3/share-offer?%s", vals.Encode()), nil)
+	client := sdk.GetAPIClient()
+	req, err := client.NewRequest("GET", "/v3/share-offer?"+params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"time"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+// SetExpiry records when the offer expires in its extra info. The expiry is not
+// enforced by the chain, the sender cancels expired offers with SweepExpiredOffers.
+func (o *OfferParams) SetExpiry(expiresAt time.Time) {
+	o.Offer.ExtraInfo.ExpiresAt = expiresAt
+}
+
+// Expired reports whether the offer has an expiry which is not after now
+func (o *TransferOffer) Expired(now time.Time) bool {
+	expiresAt := o.ExtraInfo.ExpiresAt
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+type SweepOptions struct {
+	// Now is the time offers are checked against, time.Now() if zero
+	Now time.Time
+	// DryRun reports the expired offers without cancelling them
+	DryRun bool
+}
+
+// SweepReport lists the outgoing offers of a sweep
+type SweepReport struct {
+	Expired   []*TransferOffer // all the expired offers, cancelled or not
+	Cancelled []*TransferOffer // empty on a dry run
+	Failed    map[string]error // offer ID to the cancel error
+	Pending   []*TransferOffer // not expired, or without expiry
+}
+
+// SweepExpiredOffers pages through all the outgoing offers of the sender and
+// cancels the expired ones. An error is only returned if the offers cannot be
+// listed, failed cancellations are in the report.
+func SweepExpiredOffers(sender account.Account, opts *SweepOptions) (*SweepReport, error) {
+	if sender == nil {
+		return nil, ErrNullOwner
+	}
+	if opts == nil {
+		opts = &SweepOptions{}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	offers, err := ListOutgoingOffers(sender.AccountNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SweepReport{
+		Expired:   make([]*TransferOffer, 0),
+		Cancelled: make([]*TransferOffer, 0),
+		Failed:    make(map[string]error),
+		Pending:   make([]*TransferOffer, 0),
+	}
+	for _, offer := range offers {
+		if !offer.Expired(now) {
+			report.Pending = append(report.Pending, offer)
+			continue
+		}
+		report.Expired = append(report.Expired, offer)
+		if opts.DryRun {
+			continue
+		}
+		if err := offer.Cancel(sender); err != nil {
+			report.Failed[offer.ID] = err
+			continue
+		}
+		report.Cancelled = append(report.Cancelled, offer)
+	}
+	return report, nil
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+)
+
+func TestOfferExpiry(t *testing.T) {
+	expiresAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.FixedZone("ICT", 7*3600))
+
+	params, err := NewOfferParams(receiver.AccountNumber(), map[string]interface{}{"note": "hi"})
+	assert.NoError(t, err)
+	params.SetExpiry(expiresAt)
+
+	data, err := json.Marshal(params.Offer.ExtraInfo)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"note":"hi","expires_at":"2020-06-01T05:00:00Z"}`, string(data))
+
+	var offer TransferOffer
+	assert.NoError(t, json.Unmarshal([]byte(`{"extra_info":`+string(data)+`}`), &offer))
+	assert.True(t, expiresAt.Equal(offer.ExtraInfo.ExpiresAt))
+	assert.Equal(t, RawExtraInfo{"note": "hi"}, offer.ExtraInfo.Payload)
+
+	assert.False(t, offer.Expired(expiresAt.Add(-time.Second)))
+	assert.True(t, offer.Expired(expiresAt))
+	assert.False(t, (&TransferOffer{}).Expired(expiresAt))
+
+	// typed payloads keep the expiry alongside
+	params.SetExtraInfo(&saleInfo{Price: "1"})
+	params.SetExpiry(expiresAt)
+	data, _ = json.Marshal(params.Offer.ExtraInfo)
+	assert.JSONEq(t, `{"type":"test-sale","price":"1","currency":"","expires_at":"2020-06-01T05:00:00Z"}`, string(data))
+	var info ExtraInfo
+	assert.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, &saleInfo{Price: "1"}, info.Payload)
+
+	// expiry only
+	data, _ = json.Marshal(ExtraInfo{ExpiresAt: expiresAt})
+	assert.JSONEq(t, `{"expires_at":"2020-06-01T05:00:00Z"}`, string(data))
+	assert.NoError(t, json.Unmarshal(data, &info))
+	assert.Nil(t, info.Payload)
+
+	// expiries written by other clients are kept in the payload
+	for _, foreign := range []string{`{"expires_at":1700000000}`, `{"expires_at":"tomorrow"}`} {
+		assert.NoError(t, json.Unmarshal([]byte(foreign), &info))
+		assert.True(t, info.ExpiresAt.IsZero())
+		data, _ = json.Marshal(info.Payload)
+		assert.JSONEq(t, foreign, string(data))
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"test-sale","price":"1","expires_at":1700000000}`), &info))
+	assert.Equal(t, RawExtraInfo{"type": "test-sale", "price": "1", "expires_at": float64(1700000000)}, info.Payload)
+
+	// one foreign offer does not break decoding a listing
+	var bitmarks []*Bitmark
+	assert.NoError(t, json.Unmarshal([]byte(`[{"id":"b1","offer":{"id":"o1","extra_info":{"expires_at":1700000000}}},{"id":"b2","offer":{"id":"o2","extra_info":{"expires_at":"2020-06-01T05:00:00Z"}}}]`), &bitmarks))
+	assert.True(t, bitmarks[0].Offer.ExtraInfo.ExpiresAt.IsZero())
+	assert.False(t, bitmarks[1].Offer.ExtraInfo.ExpiresAt.IsZero())
+}
+
+func TestSweepExpiredOffers(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	expired := newTestOffer(t)
+	expired.ExtraInfo.ExpiresAt = now.Add(-time.Hour)
+	failing := newTestOffer(t)
+	failing.ID = "failing"
+	failing.ExtraInfo.ExpiresAt = now.Add(-time.Minute)
+	active := newTestOffer(t)
+	active.ID = "active"
+	active.ExtraInfo.ExpiresAt = now.Add(time.Hour)
+	open := newTestOffer(t)
+	open.ID = "open"
+
+	// more offers than fit in a page, the expired ones last
+	bitmarks := make([]*Bitmark, 0)
+	for i := 0; i < 150; i++ {
+		offer := newTestOffer(t)
+		offer.ID = fmt.Sprintf("active-%d", i)
+		offer.ExtraInfo.ExpiresAt = now.Add(time.Hour)
+		bitmarks = append(bitmarks, &Bitmark{ID: fmt.Sprintf("a%d", i), Offer: offer})
+	}
+	bitmarks = append(bitmarks,
+		&Bitmark{ID: "b1", Offer: expired},
+		&Bitmark{ID: "b2", Offer: failing},
+		&Bitmark{ID: "b3", Offer: active},
+		&Bitmark{ID: "b4", Offer: open},
+	)
+	for i, bmk := range bitmarks {
+		bmk.Offset = i + 1
+	}
+
+	var cancelled []string
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			assert.Equal(t, sender.AccountNumber(), r.URL.Query().Get("offer_from"))
+			writeBitmarkPage(t, w, r, bitmarks)
+		case "PATCH":
+			var params ResponseParams
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			assert.Equal(t, Cancel, params.Action)
+			assert.Equal(t, sender.AccountNumber(), r.Header.Get("requester"))
+			if params.ID == "failing" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(sdk.APIError{Code: 1000, Message: "offer not found"})
+				return
+			}
+			cancelled = append(cancelled, params.ID)
+			json.NewEncoder(w).Encode(map[string]string{})
+		}
+	})
+	defer ts.Close()
+
+	report, err := SweepExpiredOffers(sender, &SweepOptions{Now: now, DryRun: true})
+	assert.NoError(t, err)
+	assert.Len(t, report.Expired, 2)
+	assert.Empty(t, report.Cancelled)
+	assert.Empty(t, cancelled)
+
+	report, err = SweepExpiredOffers(sender, &SweepOptions{Now: now})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{expired.ID}, cancelled)
+	if assert.Len(t, report.Cancelled, 1) {
+		assert.Equal(t, "b1", report.Cancelled[0].BitmarkID)
+	}
+	assert.Len(t, report.Expired, 2)
+	assert.IsType(t, &sdk.APIError{}, report.Failed["failing"])
+	assert.Len(t, report.Pending, 152)
+
+	_, err = SweepExpiredOffers(nil, nil)
+	assert.Equal(t, ErrNullOwner, err)
+}
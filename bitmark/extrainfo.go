@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// extraInfoTypeKey names the type of a typed payload in the extra info object
+	extraInfoTypeKey = "type"
+	// extraInfoExpiresAtKey holds the expiry of an offer, in RFC 3339 format
+	extraInfoExpiresAtKey = "expires_at"
+)
+
+var (
+	ErrExtraInfoTypeExists    = errors.New("extra info type already registered")
+	ErrInvalidExtraInfoType   = errors.New("invalid extra info type")
+	ErrUnknownExtraInfoType   = errors.New("extra info type not registered")
+	ErrExtraInfoIsNotAnObject = errors.New("extra info is not a JSON object")
+)
+
+// ExtraInfoPayload is the content of the extra info attached to an offer
+type ExtraInfoPayload interface {
+	ExtraInfoType() string
+}
+
+// RawExtraInfo is extra info of no registered type, as sent by older clients
+type RawExtraInfo map[string]interface{}
+
+func (r RawExtraInfo) ExtraInfoType() string {
+	t, _ := r[extraInfoTypeKey].(string)
+	return t
+}
+
+func rawExtraInfo(info map[string]interface{}) ExtraInfo {
+	if info == nil {
+		return ExtraInfo{}
+	}
+	return ExtraInfo{Payload: RawExtraInfo(info)}
+}
+
+// ExtraInfoCodec converts a payload type to and from its JSON object
+type ExtraInfoCodec struct {
+	Encode func(payload ExtraInfoPayload) ([]byte, error)
+	Decode func(data []byte) (ExtraInfoPayload, error)
+}
+
+// JSONExtraInfoCodec encodes payloads with encoding/json, new returns the
+// pointer to decode into
+func JSONExtraInfoCodec(new func() ExtraInfoPayload) ExtraInfoCodec {
+	return ExtraInfoCodec{
+		Encode: func(payload ExtraInfoPayload) ([]byte, error) {
+			return json.Marshal(payload)
+		},
+		Decode: func(data []byte) (ExtraInfoPayload, error) {
+			payload := new()
+			if err := json.Unmarshal(data, payload); err != nil {
+				return nil, err
+			}
+			return payload, nil
+		},
+	}
+}
+
+var (
+	extraInfoCodecs     = make(map[string]ExtraInfoCodec)
+	extraInfoCodecsLock sync.RWMutex
+)
+
+// RegisterExtraInfo makes payloads of the type decode to their Go type
+func RegisterExtraInfo(infoType string, codec ExtraInfoCodec) error {
+	if infoType == "" || codec.Encode == nil || codec.Decode == nil {
+		return ErrInvalidExtraInfoType
+	}
+
+	extraInfoCodecsLock.Lock()
+	defer extraInfoCodecsLock.Unlock()
+
+	if _, ok := extraInfoCodecs[infoType]; ok {
+		return ErrExtraInfoTypeExists
+	}
+	extraInfoCodecs[infoType] = codec
+	return nil
+}
+
+func lookupExtraInfo(infoType string) (ExtraInfoCodec, bool) {
+	extraInfoCodecsLock.RLock()
+	defer extraInfoCodecsLock.RUnlock()
+
+	codec, ok := extraInfoCodecs[infoType]
+	return codec, ok
+}
+
+// ExtraInfo is the extra info of transfer and share offers. It is sent as a JSON
+// object with the payload type under "type"; objects of a registered type decode
+// to that type, any other object to RawExtraInfo. An expiry set by the sender is
+// kept under "expires_at" next to the payload fields.
+//
+// It replaces the map[string]string of TransferOffer.ExtraInfo and the
+// json.RawMessage of ShareOffer.ExtraInfo. Code reading the old map can use
+// StringMap, or type assert the payload to RawExtraInfo; the old raw JSON is
+// json.Marshal of the ExtraInfo.
+type ExtraInfo struct {
+	Payload   ExtraInfoPayload
+	ExpiresAt time.Time
+}
+
+// StringMap returns the string fields of a RawExtraInfo payload, the extra info
+// as read before it was typed; it is nil for a typed payload or no payload
+func (e ExtraInfo) StringMap() map[string]string {
+	raw, ok := e.Payload.(RawExtraInfo)
+	if !ok {
+		return nil
+	}
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			m[k] = s
+		}
+	}
+	return m
+}
+
+func (e ExtraInfo) MarshalJSON() ([]byte, error) {
+	if e.Payload == nil && e.ExpiresAt.IsZero() {
+		return []byte("null"), nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if e.Payload != nil {
+		var data []byte
+		var err error
+		if raw, ok := e.Payload.(RawExtraInfo); ok {
+			data, err = json.Marshal(map[string]interface{}(raw))
+		} else {
+			data, err = encodeExtraInfo(e.Payload)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, ErrExtraInfoIsNotAnObject
+		}
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+	}
+	if !e.ExpiresAt.IsZero() {
+		fields[extraInfoExpiresAtKey], _ = json.Marshal(e.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return json.Marshal(fields)
+}
+
+func encodeExtraInfo(payload ExtraInfoPayload) ([]byte, error) {
+	infoType := payload.ExtraInfoType()
+	codec, ok := lookupExtraInfo(infoType)
+	if !ok {
+		return nil, ErrUnknownExtraInfoType
+	}
+	data, err := codec.Encode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, ErrExtraInfoIsNotAnObject
+	}
+	fields[extraInfoTypeKey], _ = json.Marshal(infoType)
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON never fails on the content of the object, as offers from other
+// clients must not break listing: a JSON value which is not an object is
+// dropped, and an object with an expiry which is not RFC 3339, or which its
+// registered codec cannot decode, is kept as RawExtraInfo.
+func (e *ExtraInfo) UnmarshalJSON(data []byte) error {
+	*e = ExtraInfo{}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	foreign := false
+	if value, ok := fields[extraInfoExpiresAtKey]; ok {
+		if expiresAt, err := parseExpiry(value); err == nil {
+			e.ExpiresAt = expiresAt
+			delete(fields, extraInfoExpiresAtKey)
+		} else {
+			foreign = true
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var infoType string
+	json.Unmarshal(fields[extraInfoTypeKey], &infoType)
+	if codec, ok := lookupExtraInfo(infoType); ok && !foreign {
+		typed := make(map[string]json.RawMessage, len(fields))
+		for k, v := range fields {
+			if k != extraInfoTypeKey {
+				typed[k] = v
+			}
+		}
+		encoded, _ := json.Marshal(typed)
+		if payload, err := codec.Decode(encoded); err == nil {
+			e.Payload = payload
+			return nil
+		}
+	}
+
+	remaining, _ := json.Marshal(fields)
+	var raw RawExtraInfo
+	if err := json.Unmarshal(remaining, &raw); err != nil {
+		return err
+	}
+	e.Payload = raw
+	return nil
+}
+
+func parseExpiry(value json.RawMessage) (time.Time, error) {
+	var expiresAt string
+	if err := json.Unmarshal(value, &expiresAt); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, expiresAt)
+}
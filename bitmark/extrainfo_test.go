@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type saleInfo struct {
+	Price    string `json:"price"`
+	Currency string `json:"currency"`
+}
+
+func (s *saleInfo) ExtraInfoType() string {
+	return "test-sale"
+}
+
+func init() {
+	RegisterExtraInfo("test-sale", JSONExtraInfoCodec(func() ExtraInfoPayload {
+		return &saleInfo{}
+	}))
+}
+
+func TestExtraInfoTyped(t *testing.T) {
+	params, err := NewOfferParams(receiver.AccountNumber(), nil)
+	assert.NoError(t, err)
+	params.SetExtraInfo(&saleInfo{Price: "1.5", Currency: "ETH"})
+
+	data, err := json.Marshal(params.Offer.ExtraInfo)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"test-sale","price":"1.5","currency":"ETH"}`, string(data))
+
+	var offer TransferOffer
+	assert.NoError(t, json.Unmarshal([]byte(`{"id":"o","extra_info":`+string(data)+`}`), &offer))
+	assert.Equal(t, &saleInfo{Price: "1.5", Currency: "ETH"}, offer.ExtraInfo.Payload)
+
+	var shareOffer ShareOffer
+	assert.NoError(t, json.Unmarshal([]byte(`{"id":"o","extra_info":`+string(data)+`}`), &shareOffer))
+	assert.Equal(t, &saleInfo{Price: "1.5", Currency: "ETH"}, shareOffer.ExtraInfo.Payload)
+}
+
+func TestExtraInfoRaw(t *testing.T) {
+	grant := NewShareGrantingParams("share", receiver.AccountNumber(), 1, map[string]interface{}{"note": "hi", "count": 2})
+	data, err := json.Marshal(grant.ExtraInfo)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"note":"hi","count":2}`, string(data))
+
+	var info ExtraInfo
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"unknown","note":"hi"}`), &info))
+	assert.Equal(t, RawExtraInfo{"type": "unknown", "note": "hi"}, info.Payload)
+	assert.Equal(t, "unknown", info.Payload.ExtraInfoType())
+	assert.Equal(t, map[string]string{"type": "unknown", "note": "hi"}, info.StringMap())
+	assert.Nil(t, ExtraInfo{Payload: &saleInfo{}}.StringMap())
+
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &info))
+	assert.Nil(t, info.Payload)
+	data, _ = json.Marshal(info)
+	assert.Equal(t, "null", string(data))
+
+	// values which are not objects are dropped
+	assert.NoError(t, json.Unmarshal([]byte(`"text"`), &info))
+	assert.Nil(t, info.Payload)
+
+	// payloads the registered codec cannot decode are kept raw
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"test-sale","price":15}`), &info))
+	assert.Equal(t, RawExtraInfo{"type": "test-sale", "price": float64(15)}, info.Payload)
+}
+
+type unregisteredInfo struct{}
+
+func (unregisteredInfo) ExtraInfoType() string { return "unregistered" }
+
+func TestExtraInfoRegistry(t *testing.T) {
+	codec := JSONExtraInfoCodec(func() ExtraInfoPayload { return &saleInfo{} })
+	assert.Equal(t, ErrExtraInfoTypeExists, RegisterExtraInfo("test-sale", codec))
+	assert.Equal(t, ErrInvalidExtraInfoType, RegisterExtraInfo("", codec))
+	assert.Equal(t, ErrInvalidExtraInfoType, RegisterExtraInfo("other", ExtraInfoCodec{}))
+
+	_, err := json.Marshal(ExtraInfo{Payload: unregisteredInfo{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,263 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+// MaxIssuanceBatchSize is the most issues the API accepts in one request
+const MaxIssuanceBatchSize = 100
+
+var (
+	ErrInvalidIssuanceBatchSize = errors.New("invalid issuance batch size: max = 100")
+	ErrIssuanceIncomplete       = errors.New("some issuance batches failed")
+	ErrUnexpectedIssuance       = errors.New("unexpected bitmarks in the issue response")
+)
+
+type IssuancePlanOptions struct {
+	// BatchSize is the number of issues per request, MaxIssuanceBatchSize if 0
+	BatchSize int
+	// Workers is the number of requests in flight, 1 if 0 to submit sequentially
+	Workers int
+	// Retries is the number of times a failed batch is sent again
+	Retries int
+	// RetryDelay is the wait before each retry
+	RetryDelay time.Duration
+}
+
+// IssuanceBatch is one request of an issuance plan
+type IssuanceBatch struct {
+	Params     *IssuanceParams
+	BitmarkIDs []string // computed from the signed issues, see IssuanceParams.BitmarkIDs
+	Attempts   int
+	Done       bool
+	Err        error
+}
+
+// IssuancePlan splits a large issuance into batches the API accepts. The nonces
+// are fixed when the plan is made, so a batch sent again issues the same bitmarks.
+type IssuancePlan struct {
+	Batches []*IssuanceBatch
+	opts    IssuancePlanOptions
+}
+
+// NewIssuancePlan splits issuance params, e.g. from NewIssuanceParams, into batches
+func NewIssuancePlan(params *IssuanceParams, opts *IssuancePlanOptions) (*IssuancePlan, error) {
+	plan := &IssuancePlan{}
+	if opts != nil {
+		plan.opts = *opts
+	}
+	if plan.opts.BatchSize == 0 {
+		plan.opts.BatchSize = MaxIssuanceBatchSize
+	}
+	if plan.opts.BatchSize < 0 || plan.opts.BatchSize > MaxIssuanceBatchSize {
+		return nil, ErrInvalidIssuanceBatchSize
+	}
+	if plan.opts.Workers <= 0 {
+		plan.opts.Workers = 1
+	}
+
+	issuances := params.Issuances
+	for start := 0; start < len(issuances); start += plan.opts.BatchSize {
+		end := start + plan.opts.BatchSize
+		if end > len(issuances) {
+			end = len(issuances)
+		}
+		plan.Batches = append(plan.Batches, &IssuanceBatch{
+			Params: &IssuanceParams{Issuances: issuances[start:end]},
+		})
+	}
+	return plan, nil
+}
+
+// Sign signs the issues of every batch and computes the bitmark IDs they create
+func (p *IssuancePlan) Sign(issuer account.Account) error {
+	for _, b := range p.Batches {
+		if err := b.Params.Sign(issuer); err != nil {
+			return err
+		}
+		bitmarkIDs, err := b.Params.BitmarkIDs()
+		if err != nil {
+			return err
+		}
+		b.BitmarkIDs = bitmarkIDs
+	}
+	return nil
+}
+
+// Submit sends the batches not done yet, retrying each as configured.
+// A batch holding the first issue of an asset (nonce 0) is sent before the
+// others, which depend on it, so if it fails Submit stops with its error.
+// It returns ErrIssuanceIncomplete if another batch failed, Submit can then be called again.
+func (p *IssuancePlan) Submit(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	pending := make([]*IssuanceBatch, 0, len(p.Batches))
+	for _, b := range p.Batches {
+		if b.Done {
+			continue
+		}
+		if hasFirstIssue(b) {
+			p.submitBatch(ctx, b)
+			if !b.Done {
+				return b.Err
+			}
+			continue
+		}
+		pending = append(pending, b)
+	}
+
+	batches := make(chan *IssuanceBatch)
+	var wg sync.WaitGroup
+	for w := 0; w < p.opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				p.submitBatch(ctx, b)
+			}
+		}()
+	}
+	for _, b := range pending {
+		batches <- b
+	}
+	close(batches)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, b := range p.Batches {
+		if !b.Done {
+			return ErrIssuanceIncomplete
+		}
+	}
+	return nil
+}
+
+func (p *IssuancePlan) submitBatch(ctx context.Context, b *IssuanceBatch) {
+	if b.BitmarkIDs == nil {
+		bitmarkIDs, err := b.Params.BitmarkIDs()
+		if err != nil {
+			b.Err = err
+			return
+		}
+		b.BitmarkIDs = bitmarkIDs
+	}
+
+	for attempt := 0; attempt <= p.opts.Retries; attempt++ {
+		if attempt > 0 && p.opts.RetryDelay > 0 {
+			select {
+			case <-time.After(p.opts.RetryDelay):
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			b.Err = err
+			return
+		}
+
+		b.Attempts++
+		bitmarkIDs, err := issue(ctx, b.Params)
+		if err == nil {
+			if !sameIDs(bitmarkIDs, b.BitmarkIDs) {
+				b.Err = ErrUnexpectedIssuance
+				return
+			}
+			b.Done = true
+			b.Err = nil
+			return
+		}
+
+		// an earlier attempt may have been accepted with its response lost,
+		// the resent issues are then refused as duplicates
+		if b.Attempts > 1 && issued(ctx, b.BitmarkIDs) {
+			b.Done = true
+			b.Err = nil
+			return
+		}
+		b.Err = err
+	}
+}
+
+// issued reports whether all the bitmarks are known to the API, pending or not
+func issued(ctx context.Context, bitmarkIDs []string) bool {
+	builder := NewQueryParamsBuilder().
+		BitmarkIDs(bitmarkIDs).
+		Pending(true).
+		Limit(len(bitmarkIDs))
+	bitmarks, err := list(ctx, builder)
+	if err != nil {
+		return false
+	}
+
+	found := make(map[string]bool, len(bitmarks))
+	for _, b := range bitmarks {
+		found[b.ID] = true
+	}
+	for _, bitmarkID := range bitmarkIDs {
+		if !found[bitmarkID] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameIDs reports whether a and b hold the same IDs, in any order
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, id := range a {
+		counts[id]++
+	}
+	for _, id := range b {
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+	return true
+}
+
+// BitmarkIDs returns the bitmark IDs of the batches done, in plan order
+func (p *IssuancePlan) BitmarkIDs() []string {
+	bitmarkIDs := make([]string, 0)
+	for _, b := range p.Batches {
+		if b.Done {
+			bitmarkIDs = append(bitmarkIDs, b.BitmarkIDs...)
+		}
+	}
+	return bitmarkIDs
+}
+
+// Failed returns the batches which are not done
+func (p *IssuancePlan) Failed() []*IssuanceBatch {
+	failed := make([]*IssuanceBatch, 0)
+	for _, b := range p.Batches {
+		if !b.Done {
+			failed = append(failed, b)
+		}
+	}
+	return failed
+}
+
+func hasFirstIssue(b *IssuanceBatch) bool {
+	for _, issuance := range b.Params.Issuances {
+		if issuance.Nonce == 0 {
+			return true
+		}
+	}
+	return false
+}
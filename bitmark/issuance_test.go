@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+)
+
+const issuanceAssetID = "3c50d70e0fe78819e7755687003483523852ee6ecc59fe40a4e70e89496c4d45313c6d76141bc322ba56ad3f7cd9c906b951791208281ddba3ebb5e7ad83436c"
+
+func newIssuanceParams(quantity int) *IssuanceParams {
+	params := &IssuanceParams{}
+	for i := 0; i < quantity; i++ {
+		params.Issuances = append(params.Issuances, &IssueRequest{AssetID: issuanceAssetID, Nonce: uint64(i)})
+	}
+	return params
+}
+
+// issueServer answers with the bitmark IDs of the issues sent, failing the
+// requests for which fail returns true. Requests for which lose returns true are
+// accepted but answered with an error, as when the response is lost, and issues
+// sent again are refused as duplicates. It also lists the issued bitmarks. The
+// IDs are answered in reverse order if reorder is set, and with the last one
+// replaced if tamper is set.
+type issueServer struct {
+	lock     sync.Mutex
+	requests [][]uint64
+	issued   map[string]bool
+	fail     func(nonces []uint64, attempt int) bool
+	lose     func(nonces []uint64, attempt int) bool
+	reorder  bool
+	tamper   bool
+}
+
+func (s *issueServer) start(t *testing.T) *httptest.Server {
+	s.issued = make(map[string]bool)
+	attempts := make(map[uint64]int)
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/bitmarks" {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			bitmarks := make([]*Bitmark, 0)
+			for _, id := range r.URL.Query()["bitmark_ids"] {
+				if s.issued[id] {
+					bitmarks = append(bitmarks, &Bitmark{ID: id})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"bitmarks": bitmarks})
+			return
+		}
+		assert.Equal(t, "/v3/issue", r.URL.Path)
+
+		var params IssuanceParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+
+		nonces := make([]uint64, len(params.Issuances))
+		for i, issuance := range params.Issuances {
+			nonces[i] = issuance.Nonce
+		}
+		bitmarkIDs, err := params.BitmarkIDs()
+		assert.NoError(t, err)
+
+		s.lock.Lock()
+		s.requests = append(s.requests, nonces)
+		attempts[nonces[0]]++
+		attempt := attempts[nonces[0]]
+		duplicate := s.issued[bitmarkIDs[0]]
+		failed := s.fail != nil && s.fail(nonces, attempt)
+		lost := s.lose != nil && s.lose(nonces, attempt)
+		if !duplicate && !failed {
+			for _, id := range bitmarkIDs {
+				s.issued[id] = true
+			}
+		}
+		s.lock.Unlock()
+
+		switch {
+		case duplicate:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(sdk.APIError{Code: 1000, Message: "transaction already exists"})
+			return
+		case failed, lost:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(sdk.APIError{Code: 5000, Message: "internal error"})
+			return
+		}
+
+		if s.reorder {
+			for i, j := 0, len(bitmarkIDs)-1; i < j; i, j = i+1, j-1 {
+				bitmarkIDs[i], bitmarkIDs[j] = bitmarkIDs[j], bitmarkIDs[i]
+			}
+		}
+		if s.tamper {
+			bitmarkIDs[len(bitmarkIDs)-1] = bitmarkIDs[0]
+		}
+		type item struct {
+			ID string `json:"id"`
+		}
+		result := struct {
+			Bitmarks []item `json:"bitmarks"`
+		}{}
+		for _, id := range bitmarkIDs {
+			result.Bitmarks = append(result.Bitmarks, item{ID: id})
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+func TestIssuancePlan(t *testing.T) {
+	server := &issueServer{}
+	ts := server.start(t)
+	defer ts.Close()
+
+	params := newIssuanceParams(250)
+	plan, err := NewIssuancePlan(params, &IssuancePlanOptions{Workers: 3})
+	assert.NoError(t, err)
+	assert.Len(t, plan.Batches, 3)
+	assert.NoError(t, plan.Sign(sender))
+
+	assert.NoError(t, plan.Submit(context.Background()))
+
+	// the batch with the first issue is sent alone before the others
+	assert.Len(t, server.requests, 3)
+	assert.Equal(t, uint64(0), server.requests[0][0])
+
+	expected, err := params.BitmarkIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, plan.BitmarkIDs())
+	assert.Empty(t, plan.Failed())
+}
+
+func TestIssuancePlanRetry(t *testing.T) {
+	server := &issueServer{
+		fail: func(nonces []uint64, attempt int) bool {
+			return nonces[0] == 10 && attempt == 1
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	params := newIssuanceParams(30)
+	plan, err := NewIssuancePlan(params, &IssuancePlanOptions{BatchSize: 10, Retries: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+	assert.NoError(t, plan.Submit(context.Background()))
+
+	assert.Equal(t, []int{1, 2, 1}, []int{plan.Batches[0].Attempts, plan.Batches[1].Attempts, plan.Batches[2].Attempts})
+
+	// the retry carries the same signed issues
+	assert.Equal(t, server.requests[1], server.requests[2])
+	expected, _ := params.BitmarkIDs()
+	assert.Equal(t, expected, plan.BitmarkIDs())
+}
+
+func TestIssuancePlanIncomplete(t *testing.T) {
+	server := &issueServer{
+		fail: func(nonces []uint64, attempt int) bool {
+			return nonces[0] == 20 && attempt < 3
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	params := newIssuanceParams(30)
+	plan, err := NewIssuancePlan(params, &IssuancePlanOptions{BatchSize: 10, Retries: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+
+	assert.Equal(t, ErrIssuanceIncomplete, plan.Submit(context.Background()))
+	failed := plan.Failed()
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, 2, failed[0].Attempts)
+		assert.IsType(t, &sdk.APIError{}, failed[0].Err)
+	}
+	assert.Len(t, plan.BitmarkIDs(), 20)
+
+	// submitting again only sends the failed batch
+	assert.NoError(t, plan.Submit(context.Background()))
+	assert.Len(t, server.requests, 5)
+	expected, _ := params.BitmarkIDs()
+	assert.Equal(t, expected, plan.BitmarkIDs())
+}
+
+func TestIssuancePlanLostResponse(t *testing.T) {
+	server := &issueServer{
+		lose: func(nonces []uint64, attempt int) bool {
+			return nonces[0] == 10 && attempt == 1
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	params := newIssuanceParams(30)
+	plan, err := NewIssuancePlan(params, &IssuancePlanOptions{BatchSize: 10, Retries: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+
+	// the resent batch is refused as a duplicate, the listing shows it issued
+	assert.NoError(t, plan.Submit(context.Background()))
+	assert.Equal(t, 2, plan.Batches[1].Attempts)
+	expected, _ := params.BitmarkIDs()
+	assert.Equal(t, expected, plan.BitmarkIDs())
+}
+
+func TestIssuancePlanFirstIssueFailure(t *testing.T) {
+	server := &issueServer{
+		fail: func(nonces []uint64, attempt int) bool {
+			return nonces[0] == 0
+		},
+	}
+	ts := server.start(t)
+	defer ts.Close()
+
+	plan, err := NewIssuancePlan(newIssuanceParams(30), &IssuancePlanOptions{BatchSize: 10})
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+
+	err = plan.Submit(context.Background())
+	assert.IsType(t, &sdk.APIError{}, err)
+	assert.Len(t, server.requests, 1)
+	assert.Len(t, plan.Failed(), 3)
+	assert.Empty(t, plan.BitmarkIDs())
+}
+
+func TestIssuancePlanUnexpectedResponse(t *testing.T) {
+	server := &issueServer{tamper: true}
+	ts := server.start(t)
+	defer ts.Close()
+
+	plan, err := NewIssuancePlan(newIssuanceParams(5), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+
+	assert.Equal(t, ErrUnexpectedIssuance, plan.Submit(context.Background()))
+	assert.Equal(t, ErrUnexpectedIssuance, plan.Batches[0].Err)
+}
+
+func TestIssuancePlanReorderedResponse(t *testing.T) {
+	server := &issueServer{reorder: true}
+	ts := server.start(t)
+	defer ts.Close()
+
+	params := newIssuanceParams(5)
+	plan, err := NewIssuancePlan(params, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, plan.Sign(sender))
+
+	assert.NoError(t, plan.Submit(context.Background()))
+	expected, _ := params.BitmarkIDs()
+	assert.Equal(t, expected, plan.BitmarkIDs())
+}
+
+func TestIssuancePlanInvalid(t *testing.T) {
+	_, err := NewIssuancePlan(newIssuanceParams(1), &IssuancePlanOptions{BatchSize: 101})
+	assert.Equal(t, ErrInvalidIssuanceBatchSize, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	plan, _ := NewIssuancePlan(newIssuanceParams(1), nil)
+	assert.Equal(t, context.Canceled, plan.Submit(ctx))
+}
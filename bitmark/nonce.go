@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidNonceCounter = errors.New("invalid nonce counter file")
+	ErrZeroNonce           = errors.New("nonce 0 is reserved for the first issue of an asset")
+)
+
+// NonceSource provides the nonces of issues after the first one of an asset.
+// A nonce must not be 0 and must not repeat for the same asset and owner.
+type NonceSource interface {
+	Next() (uint64, error)
+}
+
+// NonceFunc adapts a function to a NonceSource
+type NonceFunc func() (uint64, error)
+
+func (f NonceFunc) Next() (uint64, error) {
+	return f()
+}
+
+// RandomNonces draws nonces from crypto/rand, they are safe to use from any
+// number of processes
+type RandomNonces struct{}
+
+func (RandomNonces) Next() (uint64, error) {
+	var b [8]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return 0, err
+		}
+		if nonce := binary.BigEndian.Uint64(b[:]); nonce != 0 {
+			return nonce, nil
+		}
+	}
+}
+
+// FileNonceCounter is a counter persisted in a file, each nonce is written
+// before it is returned. It is safe for concurrent use within a process;
+// processes issuing at the same time must use their own files or ranges.
+type FileNonceCounter struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewFileNonceCounter uses the counter in path, which starts from 0 if the
+// file does not exist
+func NewFileNonceCounter(path string) *FileNonceCounter {
+	return &FileNonceCounter{path: path}
+}
+
+func (c *FileNonceCounter) Next() (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var current uint64
+	data, err := ioutil.ReadFile(c.path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return 0, err
+	default:
+		current, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, ErrInvalidNonceCounter
+		}
+	}
+
+	next := current + 1
+	if next == 0 {
+		return 0, ErrInvalidNonceCounter
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), ".nonce-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strconv.FormatUint(next, 10) + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
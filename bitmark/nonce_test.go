@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomNonces(t *testing.T) {
+	seen := make(map[uint64]bool)
+	for i := 0; i < 1000; i++ {
+		nonce, err := RandomNonces{}.Next()
+		assert.NoError(t, err)
+		assert.NotZero(t, nonce)
+		assert.False(t, seen[nonce])
+		seen[nonce] = true
+	}
+}
+
+func TestFileNonceCounter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nonce")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "counter")
+	counter := NewFileNonceCounter(path)
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	seen := make(map[uint64]bool)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := counter.Next()
+			assert.NoError(t, err)
+			lock.Lock()
+			seen[nonce] = true
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 20)
+
+	// the count survives a new counter on the same file
+	nonce, err := NewFileNonceCounter(path).Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21), nonce)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0644))
+	_, err = counter.Next()
+	assert.Equal(t, ErrInvalidNonceCounter, err)
+}
+
+func newFirstIssueServer(t *testing.T, response string, status int) *httptest.Server {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/bitmarks", r.URL.Path)
+		assert.Equal(t, issuanceAssetID, r.URL.Query().Get("asset_id"))
+		w.WriteHeader(status)
+		fmt.Fprintln(w, response)
+	})
+}
+
+func issueNonces(params *IssuanceParams) []uint64 {
+	nonces := make([]uint64, len(params.Issuances))
+	for i, issuance := range params.Issuances {
+		nonces[i] = issuance.Nonce
+	}
+	return nonces
+}
+
+func TestNewIssuanceParamsWithOptions(t *testing.T) {
+	ts := newFirstIssueServer(t, `{"bitmarks":[]}`, http.StatusOK)
+	defer ts.Close()
+
+	next := uint64(100)
+	source := NonceFunc(func() (uint64, error) {
+		next++
+		return next, nil
+	})
+
+	params, err := NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Quantity: 3, NonceSource: source})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0, 101, 102}, issueNonces(params))
+
+	params, err = NewIssuanceParams(issuanceAssetID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), params.Issuances[0].Nonce)
+	assert.NotZero(t, params.Issuances[1].Nonce)
+
+	_, err = NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Quantity: 3, NonceSource: NonceFunc(func() (uint64, error) {
+		return 7, nil
+	})})
+	assert.Equal(t, ErrDuplicateNonce, err)
+
+	_, err = NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Quantity: 2, NonceSource: NonceFunc(func() (uint64, error) {
+		return 0, nil
+	})})
+	assert.Equal(t, ErrZeroNonce, err)
+
+	_, err = NewIssuanceParams(issuanceAssetID, 0)
+	assert.Equal(t, ErrInvalidQuantity, err)
+}
+
+func TestNewIssuanceParamsIssued(t *testing.T) {
+	ts := newFirstIssueServer(t, `{"bitmarks":[{"id":"b"}]}`, http.StatusOK)
+	defer ts.Close()
+
+	params, err := NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Quantity: 2, NonceSource: RandomNonces{}})
+	assert.NoError(t, err)
+	assert.Len(t, params.Issuances, 2)
+	assert.NotContains(t, issueNonces(params), uint64(0))
+}
+
+func TestNewIssuanceParamsFirstIssueCheckFailed(t *testing.T) {
+	ts := newFirstIssueServer(t, `{"code":5000,"message":"internal error"}`, http.StatusInternalServerError)
+	defer ts.Close()
+
+	_, err := NewIssuanceParams(issuanceAssetID, 2)
+	assert.True(t, errors.Is(err, ErrFirstIssueCheck))
+}
+
+func TestNewIssuanceParamsNonces(t *testing.T) {
+	// explicit nonces are used as given, without the first issue check
+	params, err := NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Nonces: []uint64{0, 5, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0, 5, 3}, issueNonces(params))
+
+	_, err = NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Nonces: []uint64{1, 1}})
+	assert.Equal(t, ErrDuplicateNonce, err)
+
+	_, err = NewIssuanceParamsWithOptions(issuanceAssetID, QuantityOptions{Nonces: []uint64{1, 2}, Quantity: 3})
+	assert.Equal(t, ErrQuantityMismatch, err)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+var (
+	ErrNotOfferReceiver = errors.New("account is not the receiver of the offer")
+	ErrNotOfferSender   = errors.New("account is not the sender of the offer")
+)
+
+// ListTransferOffers returns the offers of the bitmarks matched by builder,
+// which should filter with OfferFrom or OfferTo
+func ListTransferOffers(builder *QueryParamsBuilder) ([]*TransferOffer, error) {
+	bitmarks, _, err := List(builder)
+	if err != nil {
+		return nil, err
+	}
+	return offersOf(bitmarks), nil
+}
+
+// ListIncomingOffers returns all the offers made to the receiver
+func ListIncomingOffers(receiver string) ([]*TransferOffer, error) {
+	return listAllOffers(NewQueryParamsBuilder().OfferTo(receiver))
+}
+
+// ListOutgoingOffers returns all the offers made by the sender
+func ListOutgoingOffers(sender string) ([]*TransferOffer, error) {
+	return listAllOffers(NewQueryParamsBuilder().OfferFrom(sender))
+}
+
+func listAllOffers(builder *QueryParamsBuilder) ([]*TransferOffer, error) {
+	bitmarks, err := listAllBitmarks(context.Background(), builder)
+	if err != nil {
+		return nil, err
+	}
+	return offersOf(bitmarks), nil
+}
+
+func offersOf(bitmarks []*Bitmark) []*TransferOffer {
+	offers := make([]*TransferOffer, 0, len(bitmarks))
+	for _, bmk := range bitmarks {
+		if bmk.Offer == nil {
+			continue
+		}
+		bmk.Offer.BitmarkID = bmk.ID
+		offers = append(offers, bmk.Offer)
+	}
+	return offers
+}
+
+// Accept countersigns the offer, once its signature is checked, and returns the transfer tx ID
+func (o *TransferOffer) Accept(receiver account.Account) (string, error) {
+	if receiver == nil || receiver.AccountNumber() != o.To {
+		return "", ErrNotOfferReceiver
+	}
+	if err := o.Verify(); err != nil {
+		return "", err
+	}
+	return o.respond(receiver, Accept)
+}
+
+func (o *TransferOffer) Reject(receiver account.Account) error {
+	if receiver == nil || receiver.AccountNumber() != o.To {
+		return ErrNotOfferReceiver
+	}
+	_, err := o.respond(receiver, Reject)
+	return err
+}
+
+func (o *TransferOffer) Cancel(sender account.Account) error {
+	if sender == nil || sender.AccountNumber() != o.From {
+		return ErrNotOfferSender
+	}
+	_, err := o.respond(sender, Cancel)
+	return err
+}
+
+func (o *TransferOffer) respond(acct account.Account, action OfferResponseAction) (string, error) {
+	params := newOfferResponseParams(o, action)
+	if err := params.Sign(acct); err != nil {
+		return "", err
+	}
+	return Respond(params)
+}
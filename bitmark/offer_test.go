@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const offerLink = "fa9bb80247dd0f6b3e3f21153f49fbb297b9568e67e298c96dbd75d3a348efeb"
+
+func newTestOffer(t *testing.T) *TransferOffer {
+	params, err := NewOfferParams(receiver.AccountNumber(), nil)
+	assert.NoError(t, err)
+	params.FromLatestTx(offerLink)
+	assert.NoError(t, params.Sign(sender))
+
+	return &TransferOffer{
+		ID:   "d205ed72-792f-43ca-885a-737949be6501",
+		From: sender.AccountNumber(),
+		To:   receiver.AccountNumber(),
+		Record: &CountersignedTransferRequest{
+			Link:      params.Offer.Transfer.Link,
+			Owner:     params.Offer.Transfer.Owner,
+			Signature: params.Offer.Transfer.Signature,
+		},
+	}
+}
+
+// writeBitmarkPage answers a listing paged by offset with to=later
+func writeBitmarkPage(t *testing.T, w http.ResponseWriter, r *http.Request, bitmarks []*Bitmark) {
+	query := r.URL.Query()
+	assert.Equal(t, "later", query.Get("to"))
+	at, _ := strconv.Atoi(query.Get("at"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	page := make([]*Bitmark, 0)
+	for _, bmk := range bitmarks {
+		if bmk.Offset >= at && len(page) < limit {
+			page = append(page, bmk)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"bitmarks": page})
+}
+
+func TestListOffers(t *testing.T) {
+	offer := newTestOffer(t)
+
+	// more bitmarks than fit in a page, one of them offered last
+	bitmarks := make([]*Bitmark, 0)
+	for i := 0; i < 150; i++ {
+		bitmarks = append(bitmarks, &Bitmark{ID: fmt.Sprintf("s%d", i), Status: "settled"})
+	}
+	bitmarks = append(bitmarks, &Bitmark{ID: "b1", Status: "offering", Offer: offer})
+	for i, bmk := range bitmarks {
+		bmk.Offset = i + 1
+	}
+
+	requests := 0
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v3/bitmarks", r.URL.Path)
+		q := r.URL.Query()
+		if q.Get("offer_to") != "" {
+			assert.Equal(t, receiver.AccountNumber(), q.Get("offer_to"))
+		} else {
+			assert.Equal(t, sender.AccountNumber(), q.Get("offer_from"))
+		}
+		assert.Equal(t, "100", q.Get("limit"))
+		writeBitmarkPage(t, w, r, bitmarks)
+	})
+	defer ts.Close()
+
+	offers, err := ListIncomingOffers(receiver.AccountNumber())
+	assert.NoError(t, err)
+	if assert.Len(t, offers, 1) {
+		assert.Equal(t, "b1", offers[0].BitmarkID)
+		assert.Equal(t, offer.ID, offers[0].ID)
+	}
+	assert.Equal(t, 3, requests)
+
+	offers, err = ListOutgoingOffers(sender.AccountNumber())
+	assert.NoError(t, err)
+	assert.Len(t, offers, 1)
+}
+
+func TestOfferLifecycle(t *testing.T) {
+	var actions []OfferResponseAction
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/v3/transfer", r.URL.Path)
+		assert.NotEmpty(t, r.Header.Get("signature"))
+
+		var params ResponseParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		actions = append(actions, params.Action)
+		if params.Action == Accept {
+			assert.NotEmpty(t, params.Countersignature)
+			assert.Equal(t, receiver.AccountNumber(), r.Header.Get("requester"))
+		}
+		fmt.Fprintln(w, `{"txID":"tx"}`)
+	})
+	defer ts.Close()
+
+	offer := newTestOffer(t)
+
+	txID, err := offer.Accept(receiver)
+	assert.NoError(t, err)
+	assert.Equal(t, "tx", txID)
+	assert.NoError(t, offer.Reject(receiver))
+	assert.NoError(t, offer.Cancel(sender))
+	assert.Equal(t, []OfferResponseAction{Accept, Reject, Cancel}, actions)
+
+	// the wrong party
+	_, err = offer.Accept(sender)
+	assert.Equal(t, ErrNotOfferReceiver, err)
+	assert.Equal(t, ErrNotOfferReceiver, offer.Reject(sender))
+	assert.Equal(t, ErrNotOfferSender, offer.Cancel(receiver))
+
+	// a tampered offer is not accepted
+	offer.Record.Link = proofHeadID[:62] + "00"
+	_, err = offer.Accept(receiver)
+	assert.Error(t, err)
+	assert.Len(t, actions, 3)
+}
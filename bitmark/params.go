@@ -7,10 +7,10 @@ package bitmark
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/bitmark-inc/bitmark-sdk-go/account"
@@ -23,11 +23,20 @@ const Accept OfferResponseAction = "accept"
 const Reject OfferResponseAction = "reject"
 const Cancel OfferResponseAction = "cancel"
 
-var nonceIndex uint64
+var (
+	ErrInvalidQuantity  = errors.New("quantity must be greater than or equal to 1")
+	ErrDuplicateNonce   = errors.New("duplicate nonce")
+	ErrFirstIssueCheck  = errors.New("unable to check whether the asset has been issued")
+	ErrQuantityMismatch = errors.New("quantity does not match the number of nonces")
+)
 
 type QuantityOptions struct {
-	Nonces   []uint64
+	// Nonces are used as given, the first issue check is not made
+	Nonces []uint64
+	// Quantity is the number of bitmarks to issue when Nonces is empty
 	Quantity int
+	// NonceSource provides the nonces of issues after the first, RandomNonces if nil
+	NonceSource NonceSource
 }
 
 type IssuanceParams struct {
@@ -35,23 +44,65 @@ type IssuanceParams struct {
 }
 
 type IssueRequest struct {
-	AssetID   string `json:"asset_id" pack:"hex64"`
-	Owner     string `json:"owner" pack:"account"`
-	Nonce     uint64 `json:"nonce" pack:"uint64"`
+	AssetID   string `json:"asset_id"`
+	Owner     string `json:"owner"`
+	Nonce     uint64 `json:"nonce"`
 	Signature string `json:"signature"`
 }
 
-func NewIssuanceParams(assetID string, quantity int) (*IssuanceParams, error) {
-	if quantity < 1 {
-		return nil, errors.New("quantity must be greater than or equal to 1")
+func (r *IssueRequest) RecordTag() uint64 {
+	return utils.IssueTag
+}
+
+func (r *IssueRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex64Field("AssetID", &r.AssetID),
+		utils.AccountField("Owner", &r.Owner),
+		utils.Uint64Field("Nonce", &r.Nonce),
 	}
+}
+
+func (r *IssueRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
+}
+
+func NewIssuanceParams(assetID string, quantity int) (*IssuanceParams, error) {
+	return NewIssuanceParamsWithOptions(assetID, QuantityOptions{Quantity: quantity})
+}
 
+// NewIssuanceParamsWithOptions makes the issues of an asset. Without explicit nonces
+// the first issue of an asset gets nonce 0, which requires checking the asset has
+// no bitmarks; ErrFirstIssueCheck is returned if the check fails.
+func NewIssuanceParamsWithOptions(assetID string, opts QuantityOptions) (*IssuanceParams, error) {
 	ip := &IssuanceParams{
 		Issuances: make([]*IssueRequest, 0),
 	}
 
-	builder := NewQueryParamsBuilder().ReferencedAsset(assetID)
-	bitmarks, _, _ := List(builder)
+	if len(opts.Nonces) > 0 {
+		if opts.Quantity != 0 && opts.Quantity != len(opts.Nonces) {
+			return nil, ErrQuantityMismatch
+		}
+		seen := make(map[uint64]bool, len(opts.Nonces))
+		for _, nonce := range opts.Nonces {
+			if seen[nonce] {
+				return nil, ErrDuplicateNonce
+			}
+			seen[nonce] = true
+			ip.Issuances = append(ip.Issuances, &IssueRequest{AssetID: assetID, Nonce: nonce})
+		}
+		return ip, nil
+	}
+
+	quantity := opts.Quantity
+	if quantity < 1 {
+		return nil, ErrInvalidQuantity
+	}
+
+	builder := NewQueryParamsBuilder().ReferencedAsset(assetID).Limit(1)
+	bitmarks, _, err := List(builder)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFirstIssueCheck, err)
+	}
 	if len(bitmarks) == 0 {
 		issuance := &IssueRequest{
 			AssetID: assetID,
@@ -62,9 +113,24 @@ func NewIssuanceParams(assetID string, quantity int) (*IssuanceParams, error) {
 		quantity--
 	}
 
+	source := opts.NonceSource
+	if source == nil {
+		source = RandomNonces{}
+	}
+	seen := make(map[uint64]bool, quantity)
 	for i := 0; i < quantity; i++ {
-		atomic.AddUint64(&nonceIndex, 1)
-		nonce := uint64(time.Now().UTC().Unix())*1000 + nonceIndex%1000
+		nonce, err := source.Next()
+		if err != nil {
+			return nil, err
+		}
+		if nonce == 0 {
+			return nil, ErrZeroNonce
+		}
+		if seen[nonce] {
+			return nil, ErrDuplicateNonce
+		}
+		seen[nonce] = true
+
 		issuance := &IssueRequest{
 			AssetID: assetID,
 			Nonce:   nonce,
@@ -94,13 +160,32 @@ type TransferParams struct {
 }
 
 type TransferRequest struct {
-	Link                    string   `json:"link" pack:"hex32"`
-	Escrow                  *payment `json:"-" pack:"payment"` // optional escrow payment address
-	Owner                   string   `json:"owner" pack:"account"`
+	Link                    string   `json:"link"`
+	Escrow                  *payment `json:"-"` // optional escrow payment address
+	Owner                   string   `json:"owner"`
 	Signature               string   `json:"signature"`
 	requireCountersignature bool
 }
 
+func (r *TransferRequest) RecordTag() uint64 {
+	if r.requireCountersignature {
+		return utils.CountersignedTransferTag
+	}
+	return utils.DirectTransferTag
+}
+
+func (r *TransferRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("Link", &r.Link),
+		utils.PaymentField("Escrow"),
+		utils.AccountField("Owner", &r.Owner),
+	}
+}
+
+func (r *TransferRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
+}
+
 type payment struct {
 	Currency string `json:"currency"`
 	Address  string `json:"address"`
@@ -147,11 +232,26 @@ func (t *TransferParams) Sign(sender account.Account) error {
 
 // Copy of bitmark share structure
 type ShareRequest struct {
-	Link      string `json:"link" pack:"hex32"`
-	Quantity  uint64 `json:"quantity" pack:"uint64"`
+	Link      string `json:"link"`
+	Quantity  uint64 `json:"quantity"`
 	Signature string `json:"signature"`
 }
 
+func (r *ShareRequest) RecordTag() uint64 {
+	return utils.ShareTag
+}
+
+func (r *ShareRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("Link", &r.Link),
+		utils.Uint64Field("Quantity", &r.Quantity),
+	}
+}
+
+func (r *ShareRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
+}
+
 // ShareParams is the parameter for creating shares via core api
 type ShareParams struct {
 	Share *ShareRequest `json:"share"`
@@ -188,18 +288,36 @@ func (s *ShareParams) Sign(creator account.Account) error {
 
 // Copy of bitmark share granting structure
 type GrantRequest struct {
-	ShareID     string `json:"shareID" pack:"hex32"`
-	Quantity    uint64 `json:"quantity" pack:"uint64"`
-	Owner       string `json:"owner" pack:"account"`
-	Recipient   string `json:"recipient" pack:"account"`
-	BeforeBlock uint64 `json:"beforeBlock" pack:"uint64"`
+	ShareID     string `json:"shareID"`
+	Quantity    uint64 `json:"quantity"`
+	Owner       string `json:"owner"`
+	Recipient   string `json:"recipient"`
+	BeforeBlock uint64 `json:"beforeBlock"`
 	Signature   string `json:"signature"`
 }
 
+func (r *GrantRequest) RecordTag() uint64 {
+	return utils.ShareGrantTag
+}
+
+func (r *GrantRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("ShareID", &r.ShareID),
+		utils.Uint64Field("Quantity", &r.Quantity),
+		utils.AccountField("Owner", &r.Owner),
+		utils.AccountField("Recipient", &r.Recipient),
+		utils.Uint64Field("BeforeBlock", &r.BeforeBlock),
+	}
+}
+
+func (r *GrantRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
+}
+
 // ShareGrantingParams is the parameter for granting shares to other accounts via core api
 type ShareGrantingParams struct {
-	Grant     *GrantRequest          `json:"record"`
-	ExtraInfo map[string]interface{} `json:"extra_info"`
+	Grant     *GrantRequest `json:"record"`
+	ExtraInfo ExtraInfo     `json:"extra_info"`
 }
 
 // NewShareGrantingParams returns ShareGrantingParams
@@ -210,10 +328,15 @@ func NewShareGrantingParams(shareID string, receiver string, quantity uint64, ex
 			Recipient: receiver,
 			Quantity:  quantity,
 		},
-		ExtraInfo: extraInfo,
+		ExtraInfo: rawExtraInfo(extraInfo),
 	}
 }
 
+// SetExtraInfo replaces the extra info of the grant with a typed payload
+func (s *ShareGrantingParams) SetExtraInfo(payload ExtraInfoPayload) {
+	s.ExtraInfo = ExtraInfo{Payload: payload}
+}
+
 // BeforeBlock will assign a block number which is the deadline of this request
 func (s *ShareGrantingParams) BeforeBlock(blockNumber uint64) {
 	s.Grant.BeforeBlock = blockNumber
@@ -233,15 +356,34 @@ func (s *ShareGrantingParams) Sign(granter account.Account) error {
 
 // Copy of bitmark share granting structure with counter signature
 type CountersignedGrantRequest struct {
-	ShareID          string `json:"shareID" pack:"hex32"`
-	Quantity         uint64 `json:"quantity" pack:"uint64"`
-	Owner            string `json:"owner" pack:"account"`
-	Recipient        string `json:"recipient" pack:"account"`
-	BeforeBlock      uint64 `json:"beforeBlock" pack:"uint64"`
-	Signature        string `json:"signature" pack:"hex64"`
+	ShareID          string `json:"shareID"`
+	Quantity         uint64 `json:"quantity"`
+	Owner            string `json:"owner"`
+	Recipient        string `json:"recipient"`
+	BeforeBlock      uint64 `json:"beforeBlock"`
+	Signature        string `json:"signature"`
 	Countersignature string `json:"countersignature"`
 }
 
+func (r *CountersignedGrantRequest) RecordTag() uint64 {
+	return utils.ShareGrantTag
+}
+
+func (r *CountersignedGrantRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("ShareID", &r.ShareID),
+		utils.Uint64Field("Quantity", &r.Quantity),
+		utils.AccountField("Owner", &r.Owner),
+		utils.AccountField("Recipient", &r.Recipient),
+		utils.Uint64Field("BeforeBlock", &r.BeforeBlock),
+		utils.Hex64Field("Signature", &r.Signature),
+	}
+}
+
+func (r *CountersignedGrantRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Countersignature", &r.Countersignature)}
+}
+
 // GrantResponseParams is the parameter for respond a share granting request
 type GrantResponseParams struct {
 	ID               string              `json:"id"`
@@ -267,12 +409,13 @@ func (g *GrantResponseParams) Sign(receiver account.Account) error {
 	g.auth.Add("timestamp", ts)
 	g.auth.Add("signature", sig)
 
-	message, err := utils.Pack(g.record)
-	if err != nil {
-		return err
+	if g.Action == Accept {
+		message, err := utils.Pack(g.record)
+		if err != nil {
+			return err
+		}
+		g.Countersignature = hex.EncodeToString(receiver.Sign(message))
 	}
-
-	g.Countersignature = hex.EncodeToString(receiver.Sign(message))
 	return nil
 }
 
@@ -295,14 +438,34 @@ func NewGrantResponseParams(id string, grant *GrantRequest, action OfferResponse
 
 // Copy of bitmark share swap structure
 type SwapRequest struct {
-	ShareIDOne  string `json:"shareIDOne" pack:"hex32"`   // share = issue id
-	QuantityOne uint64 `json:"quantityOne" pack:"uint64"` // shares to transfer > 0
-	OwnerOne    string `json:"ownerOne" pack:"account"`   // base58
-	ShareIDTwo  string `json:"shareIDTwo" pack:"hex32"`   // share = issue id
-	QuantityTwo uint64 `json:"quantityTwo" pack:"uint64"` // shares to transfer > 0
-	OwnerTwo    string `json:"ownerTwo" pack:"account"`   // base58
-	BeforeBlock uint64 `json:"beforeBlock" pack:"uint64"` // expires when chain height > before block
-	Signature   string `json:"signature"`                 // hex
+	ShareIDOne  string `json:"shareIDOne"`  // share = issue id
+	QuantityOne uint64 `json:"quantityOne"` // shares to transfer > 0
+	OwnerOne    string `json:"ownerOne"`    // base58
+	ShareIDTwo  string `json:"shareIDTwo"`  // share = issue id
+	QuantityTwo uint64 `json:"quantityTwo"` // shares to transfer > 0
+	OwnerTwo    string `json:"ownerTwo"`    // base58
+	BeforeBlock uint64 `json:"beforeBlock"` // expires when chain height > before block
+	Signature   string `json:"signature"`   // hex
+}
+
+func (r *SwapRequest) RecordTag() uint64 {
+	return utils.ShareSwapTag
+}
+
+func (r *SwapRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("ShareIDOne", &r.ShareIDOne),
+		utils.Uint64Field("QuantityOne", &r.QuantityOne),
+		utils.AccountField("OwnerOne", &r.OwnerOne),
+		utils.Hex32Field("ShareIDTwo", &r.ShareIDTwo),
+		utils.Uint64Field("QuantityTwo", &r.QuantityTwo),
+		utils.AccountField("OwnerTwo", &r.OwnerTwo),
+		utils.Uint64Field("BeforeBlock", &r.BeforeBlock),
+	}
+}
+
+func (r *SwapRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Signature", &r.Signature)}
 }
 
 // ShareSwapParams is the parameter for swaping shares between two accounts via core api
@@ -347,17 +510,38 @@ func NewShareSwapParams(beforeBlock uint64) *ShareSwapParams {
 
 // Copy of bitmark share swap structure with counter signature
 type CountersignedSwapRequest struct {
-	ShareIDOne       string `json:"shareIDOne" pack:"hex32"`   // share = issue id
-	QuantityOne      uint64 `json:"quantityOne" pack:"uint64"` // shares to transfer > 0
-	OwnerOne         string `json:"ownerOne" pack:"account"`   // base58
-	ShareIDTwo       string `json:"shareIDTwo" pack:"hex32"`   // share = issue id
-	QuantityTwo      uint64 `json:"quantityTwo" pack:"uint64"` // shares to transfer > 0
-	OwnerTwo         string `json:"ownerTwo" pack:"account"`   // base58
-	BeforeBlock      uint64 `json:"beforeBlock" pack:"uint64"` // expires when chain height > before block
-	Signature        string `json:"signature" pack:"hex64"`
+	ShareIDOne       string `json:"shareIDOne"`  // share = issue id
+	QuantityOne      uint64 `json:"quantityOne"` // shares to transfer > 0
+	OwnerOne         string `json:"ownerOne"`    // base58
+	ShareIDTwo       string `json:"shareIDTwo"`  // share = issue id
+	QuantityTwo      uint64 `json:"quantityTwo"` // shares to transfer > 0
+	OwnerTwo         string `json:"ownerTwo"`    // base58
+	BeforeBlock      uint64 `json:"beforeBlock"` // expires when chain height > before block
+	Signature        string `json:"signature"`
 	Countersignature string `json:"countersignature"`
 }
 
+func (r *CountersignedSwapRequest) RecordTag() uint64 {
+	return utils.ShareSwapTag
+}
+
+func (r *CountersignedSwapRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("ShareIDOne", &r.ShareIDOne),
+		utils.Uint64Field("QuantityOne", &r.QuantityOne),
+		utils.AccountField("OwnerOne", &r.OwnerOne),
+		utils.Hex32Field("ShareIDTwo", &r.ShareIDTwo),
+		utils.Uint64Field("QuantityTwo", &r.QuantityTwo),
+		utils.AccountField("OwnerTwo", &r.OwnerTwo),
+		utils.Uint64Field("BeforeBlock", &r.BeforeBlock),
+		utils.Hex64Field("Signature", &r.Signature),
+	}
+}
+
+func (r *CountersignedSwapRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Countersignature", &r.Countersignature)}
+}
+
 // ShareSwapParams is the parameter for responding swaping shares between two accounts via core api
 type SwapResponseParams struct {
 	ID               string              `json:"id"`
@@ -398,8 +582,8 @@ func NewSwapResponseParams(swap *SwapRequest, action OfferResponseAction) *SwapR
 
 type OfferParams struct {
 	Offer struct {
-		Transfer  *TransferRequest       `json:"record"`
-		ExtraInfo map[string]interface{} `json:"extra_info"`
+		Transfer  *TransferRequest `json:"record"`
+		ExtraInfo ExtraInfo        `json:"extra_info"`
 	} `json:"offer"`
 }
 
@@ -410,18 +594,23 @@ func NewOfferParams(receiver string, info map[string]interface{}) (*OfferParams,
 
 	return &OfferParams{
 		Offer: struct {
-			Transfer  *TransferRequest       `json:"record"`
-			ExtraInfo map[string]interface{} `json:"extra_info"`
+			Transfer  *TransferRequest `json:"record"`
+			ExtraInfo ExtraInfo        `json:"extra_info"`
 		}{
 			Transfer: &TransferRequest{
 				Owner:                   receiver,
 				requireCountersignature: true,
 			},
-			ExtraInfo: info,
+			ExtraInfo: rawExtraInfo(info),
 		},
 	}, nil
 }
 
+// SetExtraInfo replaces the extra info of the offer with a typed payload
+func (o *OfferParams) SetExtraInfo(payload ExtraInfoPayload) {
+	o.Offer.ExtraInfo = ExtraInfo{Payload: payload}
+}
+
 // FromBitmark sets link asynchronously
 func (o *OfferParams) FromBitmark(bitmarkID string) error {
 	bitmark, err := Get(bitmarkID)
@@ -448,13 +637,30 @@ func (o *OfferParams) Sign(sender account.Account) error {
 }
 
 type CountersignedTransferRequest struct {
-	Link             string   `json:"link" pack:"hex32"`
-	Escrow           *payment `json:"-" pack:"payment"` // optional escrow payment address
-	Owner            string   `json:"owner" pack:"account"`
-	Signature        string   `json:"signature" pack:"hex64"`
+	Link             string   `json:"link"`
+	Escrow           *payment `json:"-"` // optional escrow payment address
+	Owner            string   `json:"owner"`
+	Signature        string   `json:"signature"`
 	Countersignature string   `json:"countersignature"`
 }
 
+func (r *CountersignedTransferRequest) RecordTag() uint64 {
+	return utils.CountersignedTransferTag
+}
+
+func (r *CountersignedTransferRequest) PackFields() []utils.Field {
+	return []utils.Field{
+		utils.Hex32Field("Link", &r.Link),
+		utils.PaymentField("Escrow"),
+		utils.AccountField("Owner", &r.Owner),
+		utils.Hex64Field("Signature", &r.Signature),
+	}
+}
+
+func (r *CountersignedTransferRequest) SignatureFields() []utils.Field {
+	return []utils.Field{utils.Hex64Field("Countersignature", &r.Countersignature)}
+}
+
 type ResponseParams struct {
 	ID               string              `json:"id"`
 	Action           OfferResponseAction `json:"action"`
@@ -464,11 +670,15 @@ type ResponseParams struct {
 }
 
 func NewTransferResponseParams(bitmark *Bitmark, action OfferResponseAction) *ResponseParams {
+	return newOfferResponseParams(bitmark.Offer, action)
+}
+
+func newOfferResponseParams(offer *TransferOffer, action OfferResponseAction) *ResponseParams {
 	return &ResponseParams{
-		ID:     bitmark.Offer.ID,
+		ID:     offer.ID,
 		Action: action,
 		auth:   make(http.Header),
-		record: bitmark.Offer.Record,
+		record: offer.Record,
 	}
 }
 
@@ -6,6 +6,8 @@ package bitmark
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
@@ -29,6 +31,17 @@ func init() {
 	receiver, _ = account.FromSeed(receiverSeed)
 }
 
+// newTestServer starts a server for the handler and points the API client at it
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	ts := httptest.NewServer(handler)
+	sdk.Init(&sdk.Config{
+		HTTPClient: ts.Client(),
+		Network:    sdk.Testnet,
+	})
+	sdk.GetAPIClient().URLAuthority = ts.URL
+	return ts
+}
+
 // func TestIssunaceParams(t *testing.T) {
 // 	assetID := "3c50d70e0fe78819e7755687003483523852ee6ecc59fe40a4e70e89496c4d45313c6d76141bc322ba56ad3f7cd9c906b951791208281ddba3ebb5e7ad83436c"
 // 	params := NewIssuanceParams(assetID, QuantityOptions{Nonces: []uint64{1, 2, 3}})
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+const (
+	ownershipProofAction = "ownershipProof"
+	// maxProofClockSkew is how far in the future a proof timestamp may be
+	maxProofClockSkew = 5 * time.Minute
+)
+
+var (
+	ErrProofNotSigned = errors.New("ownership proof not signed")
+	ErrNullOwner      = errors.New("owner is null")
+	ErrNullProof      = errors.New("ownership proof is null")
+)
+
+// OwnershipProof is a statement signed by the owner of a bitmark which binds
+// the bitmark ID, the head transaction ID and the time it was produced
+type OwnershipProof struct {
+	BitmarkID  string `json:"bitmark_id"`
+	LatestTxID string `json:"head_id"`
+	Owner      string `json:"owner"`
+	Timestamp  int64  `json:"timestamp"` // milliseconds since epoch
+	Signature  string `json:"signature"`
+}
+
+// OwnershipVerdict reports the result of checking an ownership proof
+type OwnershipVerdict struct {
+	Valid          bool   `json:"valid"`
+	SignatureValid bool   `json:"signature_valid"`
+	OwnerCurrent   bool   `json:"owner_current"`
+	HeadCurrent    bool   `json:"head_current"`
+	Expired        bool   `json:"expired"`
+	CurrentOwner   string `json:"current_owner"`
+	CurrentTxID    string `json:"current_head_id"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// NewOwnershipProof returns an unsigned proof for the given bitmark and head tx
+func NewOwnershipProof(bitmarkID, latestTxID string) *OwnershipProof {
+	return &OwnershipProof{
+		BitmarkID:  bitmarkID,
+		LatestTxID: latestTxID,
+	}
+}
+
+// ProveOwnership looks up the current head of the bitmark and signs a proof with the owner's account
+func ProveOwnership(bitmarkID string, owner account.Account) (*OwnershipProof, error) {
+	bitmark, err := Get(bitmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := NewOwnershipProof(bitmarkID, bitmark.LatestTxID)
+	if err := proof.Sign(owner); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Sign will generate the signature for an ownership proof
+func (p *OwnershipProof) Sign(owner account.Account) error {
+	if owner == nil {
+		return ErrNullOwner
+	}
+
+	p.Owner = owner.AccountNumber()
+	p.Timestamp = time.Now().UnixNano() / 1000000
+	p.Signature = hex.EncodeToString(owner.Sign(p.message()))
+	return nil
+}
+
+// VerifySignature checks the proof signature against the owner's account number only
+func (p *OwnershipProof) VerifySignature() error {
+	if p.Signature == "" {
+		return ErrProofNotSigned
+	}
+
+	sig, err := hex.DecodeString(p.Signature)
+	if err != nil {
+		return err
+	}
+
+	return account.Verify(p.Owner, p.message(), sig)
+}
+
+func (p *OwnershipProof) message() []byte {
+	parts := []string{
+		ownershipProofAction,
+		p.BitmarkID,
+		p.LatestTxID,
+		p.Owner,
+		strconv.FormatInt(p.Timestamp, 10),
+	}
+	return []byte(strings.Join(parts, "|"))
+}
+
+// VerifyOwnershipProof checks the signature of the proof and queries the bitmark to
+// confirm the owner and head tx are still current. A proof older than maxAge is
+// reported as expired; a zero maxAge disables the age check. A proof signed more
+// than a few minutes in the future is never valid.
+func VerifyOwnershipProof(proof *OwnershipProof, maxAge time.Duration) (*OwnershipVerdict, error) {
+	if proof == nil {
+		return nil, ErrNullProof
+	}
+
+	verdict := &OwnershipVerdict{}
+
+	if err := proof.VerifySignature(); err != nil {
+		verdict.Reason = err.Error()
+		return verdict, nil
+	}
+	verdict.SignatureValid = true
+
+	signedAt := time.Unix(0, proof.Timestamp*int64(time.Millisecond))
+	if signedAt.After(time.Now().Add(maxProofClockSkew)) {
+		verdict.Reason = "proof timestamp is in the future"
+		return verdict, nil
+	}
+	if maxAge > 0 {
+		verdict.Expired = time.Since(signedAt) > maxAge
+	}
+
+	bitmark, err := Get(proof.BitmarkID)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict.CurrentOwner = bitmark.Owner
+	verdict.CurrentTxID = bitmark.LatestTxID
+	verdict.OwnerCurrent = bitmark.Owner == proof.Owner
+	verdict.HeadCurrent = bitmark.LatestTxID == proof.LatestTxID
+
+	switch {
+	case !verdict.OwnerCurrent:
+		verdict.Reason = "owner is no longer current"
+	case !verdict.HeadCurrent:
+		verdict.Reason = "head tx is no longer current"
+	case verdict.Expired:
+		verdict.Reason = "proof expired"
+	default:
+		verdict.Valid = true
+	}
+
+	return verdict, nil
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	proofBitmarkID = "8c5c3c38c6cf6e8a5d9b1b52b5e5e1cbcc0d0d43f6c4e0b4bd1f7e70e2a4c3d1"
+	proofHeadID    = "fa9bb80247dd0f6b3e3f21153f49fbb297b9568e67e298c96dbd75d3a348efeb"
+)
+
+func newBitmarkServer(t *testing.T, owner, headID string) *httptest.Server {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/bitmarks/"+proofBitmarkID, r.URL.Path)
+		fmt.Fprintf(w, `{"bitmark":{"id":"%s","head_id":"%s","owner":"%s","status":"settled"}}`, proofBitmarkID, headID, owner)
+	})
+}
+
+func TestOwnershipProof(t *testing.T) {
+	ts := newBitmarkServer(t, sender.AccountNumber(), proofHeadID)
+	defer ts.Close()
+
+	proof, err := ProveOwnership(proofBitmarkID, sender)
+	assert.NoError(t, err)
+	assert.Equal(t, proofHeadID, proof.LatestTxID)
+	assert.Equal(t, sender.AccountNumber(), proof.Owner)
+	assert.NoError(t, proof.VerifySignature())
+
+	verdict, err := VerifyOwnershipProof(proof, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, verdict.Valid)
+	assert.True(t, verdict.SignatureValid)
+	assert.True(t, verdict.OwnerCurrent)
+	assert.True(t, verdict.HeadCurrent)
+	assert.False(t, verdict.Expired)
+}
+
+func TestOwnershipProofTampered(t *testing.T) {
+	ts := newBitmarkServer(t, sender.AccountNumber(), proofHeadID)
+	defer ts.Close()
+
+	proof := NewOwnershipProof(proofBitmarkID, proofHeadID)
+	assert.EqualError(t, proof.VerifySignature(), ErrProofNotSigned.Error())
+	assert.NoError(t, proof.Sign(sender))
+
+	proof.Owner = receiver.AccountNumber()
+	verdict, err := VerifyOwnershipProof(proof, 0)
+	assert.NoError(t, err)
+	assert.False(t, verdict.Valid)
+	assert.False(t, verdict.SignatureValid)
+
+	assert.EqualError(t, proof.Sign(nil), ErrNullOwner.Error())
+}
+
+func TestOwnershipProofStale(t *testing.T) {
+	ts := newBitmarkServer(t, receiver.AccountNumber(), "67ef8bfee0ef7b8c33eda34ba21c8b2b0fbff601a7021984b2e27985251a0a80")
+	defer ts.Close()
+
+	proof := NewOwnershipProof(proofBitmarkID, proofHeadID)
+	assert.NoError(t, proof.Sign(sender))
+	proof.Timestamp -= int64(time.Hour / time.Millisecond)
+	proof.Signature = hex.EncodeToString(sender.Sign(proof.message()))
+
+	verdict, err := VerifyOwnershipProof(proof, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, verdict.Valid)
+	assert.True(t, verdict.SignatureValid)
+	assert.True(t, verdict.Expired)
+	assert.False(t, verdict.OwnerCurrent)
+	assert.False(t, verdict.HeadCurrent)
+	assert.Equal(t, receiver.AccountNumber(), verdict.CurrentOwner)
+}
+
+func TestOwnershipProofFuture(t *testing.T) {
+	ts := newBitmarkServer(t, sender.AccountNumber(), proofHeadID)
+	defer ts.Close()
+
+	proof := NewOwnershipProof(proofBitmarkID, proofHeadID)
+	assert.NoError(t, proof.Sign(sender))
+	proof.Timestamp += int64(time.Hour / time.Millisecond)
+	proof.Signature = hex.EncodeToString(sender.Sign(proof.message()))
+
+	verdict, err := VerifyOwnershipProof(proof, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, verdict.Valid)
+	assert.True(t, verdict.SignatureValid)
+	assert.Equal(t, "proof timestamp is in the future", verdict.Reason)
+
+	// a little clock skew is allowed
+	proof.Timestamp -= int64(59 * time.Minute / time.Millisecond)
+	proof.Signature = hex.EncodeToString(sender.Sign(proof.message()))
+	verdict, err = VerifyOwnershipProof(proof, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, verdict.Valid)
+
+	_, err = VerifyOwnershipProof(nil, time.Minute)
+	assert.Equal(t, ErrNullProof, err)
+}
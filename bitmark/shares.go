@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+var ErrShareNotFound = errors.New("share not found")
+
+// ShareOfferQueryBuilder filters share offers by sender, receiver and share.
+// Offers are paged by offset from At on; "to" names the receiver in this API so
+// there is no direction.
+type ShareOfferQueryBuilder struct {
+	params url.Values
+	err    error
+}
+
+func NewShareOfferQueryBuilder() *ShareOfferQueryBuilder {
+	return &ShareOfferQueryBuilder{params: url.Values{}}
+}
+
+func (sb *ShareOfferQueryBuilder) OfferFrom(sender string) *ShareOfferQueryBuilder {
+	sb.params.Set("from", sender)
+	return sb
+}
+
+func (sb *ShareOfferQueryBuilder) OfferTo(receiver string) *ShareOfferQueryBuilder {
+	sb.params.Set("to", receiver)
+	return sb
+}
+
+func (sb *ShareOfferQueryBuilder) ShareID(shareID string) *ShareOfferQueryBuilder {
+	sb.params.Set("share_id", shareID)
+	return sb
+}
+
+func (sb *ShareOfferQueryBuilder) Limit(size int) *ShareOfferQueryBuilder {
+	if size > 100 {
+		sb.err = errors.New("invalid size: max = 100")
+	}
+	sb.params.Set("limit", strconv.Itoa(size))
+	return sb
+}
+
+func (sb *ShareOfferQueryBuilder) At(at int) *ShareOfferQueryBuilder {
+	sb.params.Set("at", strconv.Itoa(at))
+	return sb
+}
+
+func (sb *ShareOfferQueryBuilder) Build() (string, error) {
+	if sb.err != nil {
+		return "", sb.err
+	}
+	return sb.params.Encode(), nil
+}
+
+// Accept countersigns the grant, once its signature is checked, and returns the grant tx ID
+func (o *ShareOffer) Accept(receiver account.Account) (string, error) {
+	if receiver == nil || receiver.AccountNumber() != o.To {
+		return "", ErrNotOfferReceiver
+	}
+	if err := o.Verify(); err != nil {
+		return "", err
+	}
+	return o.respond(receiver, Accept)
+}
+
+func (o *ShareOffer) Reject(receiver account.Account) error {
+	if receiver == nil || receiver.AccountNumber() != o.To {
+		return ErrNotOfferReceiver
+	}
+	_, err := o.respond(receiver, Reject)
+	return err
+}
+
+// Cancel withdraws a pending grant, making the shares it held available again
+func (o *ShareOffer) Cancel(owner account.Account) error {
+	if owner == nil || owner.AccountNumber() != o.From {
+		return ErrNotOfferSender
+	}
+	_, err := o.respond(owner, Cancel)
+	return err
+}
+
+func (o *ShareOffer) respond(acct account.Account, action OfferResponseAction) (string, error) {
+	params := NewGrantResponseParams(o.ID, &o.Record, action)
+	if err := params.Sign(acct); err != nil {
+		return "", err
+	}
+	return ReplyShareOffer(params)
+}
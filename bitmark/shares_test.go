@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testShareID = "630c041cd1f586bcb9097e816189185c1e0379f67bbfc2f0626724f542047873"
+
+func TestShareBalances(t *testing.T) {
+	// 150 holders, more than fit in a page
+	holders := make([]*Share, 150)
+	for i := range holders {
+		holders[i] = &Share{ID: testShareID, Owner: fmt.Sprintf("owner-%d", i), Balance: 5, Available: 5, Offset: i + 1}
+	}
+	holders[0].Owner = "a"
+	holders[0].Balance, holders[0].Available = 100, 60
+
+	var queries []url.Values
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/shares", r.URL.Path)
+		q := r.URL.Query()
+		queries = append(queries, q)
+
+		shares := make([]*Share, 0)
+		at, _ := strconv.Atoi(q.Get("at"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		for _, share := range holders {
+			if owner := q.Get("owner"); owner != "" && share.Owner != owner {
+				continue
+			}
+			if q.Get("limit") != "" && (share.Offset < at || len(shares) == limit) {
+				continue
+			}
+			shares = append(shares, share)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"shares": shares})
+	})
+	defer ts.Close()
+
+	shares, err := ListShareHolders(testShareID)
+	assert.NoError(t, err)
+	if assert.Len(t, shares, 150) {
+		assert.Equal(t, uint64(40), shares[0].Pending())
+		assert.Equal(t, uint64(0), shares[1].Pending())
+	}
+	assert.Len(t, queries, 3)
+	assert.Equal(t, url.Values{"share_id": {testShareID}, "at": {"0"}, "to": {"later"}, "limit": {"100"}}, queries[0])
+	assert.Equal(t, "101", queries[1].Get("at"))
+
+	queries = nil
+	shares, err = ListShareBalances("a")
+	assert.NoError(t, err)
+	assert.Len(t, shares, 1)
+	assert.Equal(t, "a", queries[0].Get("owner"))
+
+	share, err := GetShareBalance(testShareID, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(60), share.Available)
+	assert.Equal(t, url.Values{"share_id": {testShareID}, "owner": {"a"}}, queries[len(queries)-1])
+
+	_, err = GetShareBalance(testShareID, "nobody")
+	assert.Equal(t, ErrShareNotFound, err)
+}
+
+func TestListShareOffers(t *testing.T) {
+	// 150 offers, more than fit in a page
+	offers := make([]*ShareOffer, 150)
+	for i := range offers {
+		offers[i] = &ShareOffer{ID: fmt.Sprintf("o%d", i), ShareID: testShareID, From: "a", To: "b", Offset: i + 1}
+	}
+
+	var queries []url.Values
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/share-offer", r.URL.Path)
+		q := r.URL.Query()
+		queries = append(queries, q)
+
+		page := make([]*ShareOffer, 0)
+		at, _ := strconv.Atoi(q.Get("at"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		for _, offer := range offers {
+			if offer.Offset >= at && len(page) < limit {
+				page = append(page, offer)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"offers": page})
+	})
+	defer ts.Close()
+
+	listed, err := ListShareOffers("a", "")
+	assert.NoError(t, err)
+	if assert.Len(t, listed, 150) {
+		assert.Equal(t, "o149", listed[149].ID)
+	}
+	assert.Len(t, queries, 3)
+	assert.Equal(t, url.Values{"from": {"a"}, "at": {"0"}, "limit": {"100"}}, queries[0])
+	assert.Equal(t, "101", queries[1].Get("at"))
+
+	queries = nil
+	listed, err = ListShareOffersWithParams(NewShareOfferQueryBuilder().OfferFrom("a").OfferTo("b").ShareID(testShareID).At(140).Limit(20))
+	assert.NoError(t, err)
+	assert.Len(t, listed, 11)
+	assert.Equal(t, url.Values{"from": {"a"}, "to": {"b"}, "share_id": {testShareID}, "at": {"140"}, "limit": {"20"}}, queries[0])
+
+	_, err = ListShareOffersWithParams(NewShareOfferQueryBuilder().Limit(101))
+	assert.EqualError(t, err, "invalid size: max = 100")
+}
+
+func TestShareOfferLifecycle(t *testing.T) {
+	grant := NewShareGrantingParams(testShareID, receiver.AccountNumber(), 10, nil)
+	grant.BeforeBlock(1000)
+	assert.NoError(t, grant.Sign(sender))
+
+	offer := &ShareOffer{
+		ID:      "grant-offer",
+		ShareID: testShareID,
+		From:    sender.AccountNumber(),
+		To:      receiver.AccountNumber(),
+		Record:  *grant.Grant,
+	}
+
+	var responses []GrantResponseParams
+	ts := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/v3/share-offer", r.URL.Path)
+
+		var params GrantResponseParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		assert.Equal(t, "grant-offer", params.ID)
+		responses = append(responses, params)
+		fmt.Fprintln(w, `{"txID":"tx"}`)
+	})
+	defer ts.Close()
+
+	txID, err := offer.Accept(receiver)
+	assert.NoError(t, err)
+	assert.Equal(t, "tx", txID)
+	assert.NoError(t, offer.Reject(receiver))
+	assert.NoError(t, offer.Cancel(sender))
+
+	if assert.Len(t, responses, 3) {
+		assert.Equal(t, Accept, responses[0].Action)
+		assert.NotEmpty(t, responses[0].Countersignature)
+		assert.Equal(t, Reject, responses[1].Action)
+		assert.Empty(t, responses[1].Countersignature)
+		assert.Equal(t, Cancel, responses[2].Action)
+		assert.Empty(t, responses[2].Countersignature)
+	}
+
+	assert.Equal(t, ErrNotOfferSender, offer.Cancel(receiver))
+	_, err = offer.Accept(sender)
+	assert.Equal(t, ErrNotOfferReceiver, err)
+
+	offer.Record.Quantity = 1000
+	_, err = offer.Accept(receiver)
+	assert.Error(t, err)
+	assert.Len(t, responses, 3)
+}
@@ -5,7 +5,6 @@
 package bitmark
 
 import (
-	"encoding/json"
 	"time"
 )
 
@@ -26,26 +25,39 @@ type Bitmark struct {
 
 type TransferOffer struct {
 	ID        string                        `json:"id"`
+	BitmarkID string                        `json:"bitmark_id"`
 	From      string                        `json:"from"`
 	To        string                        `json:"to"`
 	Record    *CountersignedTransferRequest `json:"record"`
-	ExtraInfo map[string]string             `json:"extra_info"`
+	ExtraInfo ExtraInfo                     `json:"extra_info"`
 	CreatedAt time.Time                     `json:"created_at"`
 }
 
+// Share is the balance of a share held by an owner; the part of the balance
+// not available is held by pending grants and swaps
 type Share struct {
 	ID        string `json:"share_id"`
 	Owner     string `json:"owner"`
 	Balance   uint64 `json:"balance"`
 	Available uint64 `json:"available"`
+	Offset    int    `json:"offset"`
+}
+
+// Pending is the amount held by grants and swaps not yet accepted
+func (s *Share) Pending() uint64 {
+	if s.Available > s.Balance {
+		return 0
+	}
+	return s.Balance - s.Available
 }
 
 type ShareOffer struct {
-	ID        string          `json:"id"`
-	ShareID   string          `json:"share_id"`
-	From      string          `json:"from"`
-	To        string          `json:"to"`
-	Record    GrantRequest    `json:"record"`
-	ExtraInfo json.RawMessage `json:"extra_info"`
-	CreatedAt time.Time       `json:"created_at"`
+	ID        string       `json:"id"`
+	ShareID   string       `json:"share_id"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Record    GrantRequest `json:"record"`
+	ExtraInfo ExtraInfo    `json:"extra_info"`
+	CreatedAt time.Time    `json:"created_at"`
+	Offset    int          `json:"offset"`
 }
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+// BitmarkID computes the ID of the bitmark created by a signed issue, i.e. its tx ID
+func (r *IssueRequest) BitmarkID() (string, error) {
+	return utils.TxID(r)
+}
+
+// BitmarkIDs computes the bitmark IDs of all issues in a signed batch
+func (p *IssuanceParams) BitmarkIDs() ([]string, error) {
+	bitmarkIDs := make([]string, len(p.Issuances))
+	for i, issuance := range p.Issuances {
+		bitmarkID, err := issuance.BitmarkID()
+		if err != nil {
+			return nil, err
+		}
+		bitmarkIDs[i] = bitmarkID
+	}
+	return bitmarkIDs, nil
+}
+
+// TxID computes the tx ID of a signed direct transfer. The tx ID of an offer is
+// only known once it is countersigned, see CountersignedTransferRequest.TxID.
+func (r *TransferRequest) TxID() (string, error) {
+	if r.requireCountersignature {
+		return "", utils.ErrMissingCountersignature
+	}
+	return utils.TxID(r)
+}
+
+// TxID computes the tx ID of a countersigned transfer
+func (r *CountersignedTransferRequest) TxID() (string, error) {
+	return utils.TxID(r)
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"errors"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+var (
+	ErrMissingOfferRecord  = errors.New("offer has no record")
+	ErrOfferRecordMismatch = errors.New("offer record does not match the offer")
+)
+
+// Verify checks the signature of the issue against its owner
+func (r *IssueRequest) Verify() error {
+	return utils.VerifySignature(r, r.Owner, r.Signature)
+}
+
+// Verify checks the signature of the transfer against the current owner of the bitmark,
+// which is not part of the record
+func (r *TransferRequest) Verify(sender string) error {
+	return utils.VerifySignature(r, sender, r.Signature)
+}
+
+// Verify checks the signature against the sender and, if present, the countersignature
+// against the receiver, i.e. the new owner
+func (r *CountersignedTransferRequest) Verify(sender string) error {
+	unsigned := &TransferRequest{
+		Link:                    r.Link,
+		Escrow:                  r.Escrow,
+		Owner:                   r.Owner,
+		requireCountersignature: true,
+	}
+	if err := utils.VerifySignature(unsigned, sender, r.Signature); err != nil {
+		return err
+	}
+
+	if r.Countersignature == "" {
+		return nil
+	}
+	return utils.VerifyCountersignature(r, r.Owner, r.Countersignature)
+}
+
+// Verify checks the signature of the share request against the owner of the bitmark
+func (r *ShareRequest) Verify(owner string) error {
+	return utils.VerifySignature(r, owner, r.Signature)
+}
+
+// Verify checks the signature of the grant against the share owner
+func (r *GrantRequest) Verify() error {
+	return utils.VerifySignature(r, r.Owner, r.Signature)
+}
+
+// Verify checks the signature against the share owner and, if present,
+// the countersignature against the recipient
+func (r *CountersignedGrantRequest) Verify() error {
+	unsigned := &GrantRequest{
+		ShareID:     r.ShareID,
+		Quantity:    r.Quantity,
+		Owner:       r.Owner,
+		Recipient:   r.Recipient,
+		BeforeBlock: r.BeforeBlock,
+	}
+	if err := utils.VerifySignature(unsigned, r.Owner, r.Signature); err != nil {
+		return err
+	}
+
+	if r.Countersignature == "" {
+		return nil
+	}
+	return utils.VerifyCountersignature(r, r.Recipient, r.Countersignature)
+}
+
+// Verify checks the signature of the swap against the first owner
+func (r *SwapRequest) Verify() error {
+	return utils.VerifySignature(r, r.OwnerOne, r.Signature)
+}
+
+// Verify checks the signature against the first owner and, if present,
+// the countersignature against the second owner
+func (r *CountersignedSwapRequest) Verify() error {
+	unsigned := &SwapRequest{
+		ShareIDOne:  r.ShareIDOne,
+		QuantityOne: r.QuantityOne,
+		OwnerOne:    r.OwnerOne,
+		ShareIDTwo:  r.ShareIDTwo,
+		QuantityTwo: r.QuantityTwo,
+		OwnerTwo:    r.OwnerTwo,
+		BeforeBlock: r.BeforeBlock,
+	}
+	if err := utils.VerifySignature(unsigned, r.OwnerOne, r.Signature); err != nil {
+		return err
+	}
+
+	if r.Countersignature == "" {
+		return nil
+	}
+	return utils.VerifyCountersignature(r, r.OwnerTwo, r.Countersignature)
+}
+
+// Verify checks that the offer record transfers the bitmark from the sender to
+// the receiver of the offer and is signed by the sender
+func (o *TransferOffer) Verify() error {
+	if o.Record == nil {
+		return ErrMissingOfferRecord
+	}
+	if o.Record.Owner != o.To {
+		return ErrOfferRecordMismatch
+	}
+	return o.Record.Verify(o.From)
+}
+
+// Verify checks that the offer record grants the share from the sender to
+// the receiver of the offer and is signed by the sender
+func (o *ShareOffer) Verify() error {
+	if o.Record.Owner != o.From || o.Record.Recipient != o.To {
+		return ErrOfferRecordMismatch
+	}
+	if o.ShareID != "" && o.Record.ShareID != o.ShareID {
+		return ErrOfferRecordMismatch
+	}
+	return o.Record.Verify()
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package bitmark
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+const (
+	verifyTxID    = "67ef8bfee0ef7b8c33eda34ba21c8b2b0fbff601a7021984b2e27985251a0a80"
+	verifyAssetID = "3c50d70e0fe78819e7755687003483523852ee6ecc59fe40a4e70e89496c4d45313c6d76141bc322ba56ad3f7cd9c906b951791208281ddba3ebb5e7ad83436c"
+)
+
+func TestVerifyIssue(t *testing.T) {
+	params := &IssuanceParams{Issuances: []*IssueRequest{{AssetID: verifyAssetID, Nonce: 1}}}
+	assert.NoError(t, params.Sign(sender))
+
+	issue := params.Issuances[0]
+	assert.NoError(t, issue.Verify())
+
+	issue.Nonce = 2
+	assert.EqualError(t, issue.Verify(), utils.ErrInvalidSignature.Error())
+}
+
+func TestVerifyTransfer(t *testing.T) {
+	params, err := NewTransferParams(receiver.AccountNumber())
+	assert.NoError(t, err)
+	params.FromLatestTx(verifyTxID)
+	assert.EqualError(t, params.Transfer.Verify(sender.AccountNumber()), utils.ErrMissingSignature.Error())
+
+	assert.NoError(t, params.Sign(sender))
+	assert.NoError(t, params.Transfer.Verify(sender.AccountNumber()))
+	assert.EqualError(t, params.Transfer.Verify(receiver.AccountNumber()), utils.ErrInvalidSignature.Error())
+}
+
+func TestVerifyTransferOffer(t *testing.T) {
+	params, err := NewOfferParams(receiver.AccountNumber(), nil)
+	assert.NoError(t, err)
+	params.FromLatestTx(verifyTxID)
+	assert.NoError(t, params.Sign(sender))
+
+	offer := &TransferOffer{
+		ID:   "d205ed72-792f-43ca-885a-737949be6501",
+		From: sender.AccountNumber(),
+		To:   receiver.AccountNumber(),
+		Record: &CountersignedTransferRequest{
+			Link:      params.Offer.Transfer.Link,
+			Owner:     params.Offer.Transfer.Owner,
+			Signature: params.Offer.Transfer.Signature,
+		},
+	}
+	assert.NoError(t, offer.Verify())
+
+	// a direct transfer signature does not verify as an offer
+	direct, _ := NewTransferParams(receiver.AccountNumber())
+	direct.FromLatestTx(verifyTxID)
+	assert.NoError(t, direct.Sign(sender))
+	tampered := *offer.Record
+	tampered.Signature = direct.Transfer.Signature
+	assert.EqualError(t, tampered.Verify(sender.AccountNumber()), utils.ErrInvalidSignature.Error())
+
+	// the offer must go to the new owner of the record
+	redirected := *offer
+	redirected.To = sender.AccountNumber()
+	assert.EqualError(t, redirected.Verify(), ErrOfferRecordMismatch.Error())
+	assert.EqualError(t, (&TransferOffer{}).Verify(), ErrMissingOfferRecord.Error())
+
+	response := NewTransferResponseParams(&Bitmark{Offer: offer}, Accept)
+	assert.NoError(t, response.Sign(receiver))
+	offer.Record.Countersignature = response.Countersignature
+	assert.NoError(t, offer.Verify())
+
+	offer.Record.Countersignature = hex.EncodeToString(sender.Sign([]byte("not the record")))
+	assert.EqualError(t, offer.Verify(), utils.ErrInvalidCountersignature.Error())
+}
+
+func TestVerifyShares(t *testing.T) {
+	share := NewShareParams(100)
+	share.Share.Link = verifyTxID
+	assert.NoError(t, share.Sign(sender))
+	assert.NoError(t, share.Share.Verify(sender.AccountNumber()))
+
+	grant := NewShareGrantingParams(verifyTxID, receiver.AccountNumber(), 10, nil)
+	grant.BeforeBlock(1000)
+	assert.NoError(t, grant.Sign(sender))
+	assert.NoError(t, grant.Grant.Verify())
+
+	offer := &ShareOffer{
+		ShareID: verifyTxID,
+		From:    sender.AccountNumber(),
+		To:      receiver.AccountNumber(),
+		Record:  *grant.Grant,
+	}
+	assert.NoError(t, offer.Verify())
+
+	offer.Record.Quantity = 1000
+	assert.EqualError(t, offer.Verify(), utils.ErrInvalidSignature.Error())
+	offer.Record.Quantity = 10
+	offer.To = sender.AccountNumber()
+	assert.EqualError(t, offer.Verify(), ErrOfferRecordMismatch.Error())
+
+	response := NewGrantResponseParams("offer", grant.Grant, Accept)
+	assert.NoError(t, response.Sign(receiver))
+	countersigned := *response.record
+	countersigned.Countersignature = response.Countersignature
+	assert.NoError(t, countersigned.Verify())
+	countersigned.BeforeBlock = 1001
+	assert.EqualError(t, countersigned.Verify(), utils.ErrInvalidSignature.Error())
+
+	swap := NewShareSwapParams(1000).
+		FromShare(verifyTxID, sender.AccountNumber(), 1).
+		ToShare("fa9bb80247dd0f6b3e3f21153f49fbb297b9568e67e298c96dbd75d3a348efeb", receiver.AccountNumber(), 2)
+	assert.NoError(t, swap.Sign(sender))
+	assert.NoError(t, swap.Swap.Verify())
+
+	swapResponse := NewSwapResponseParams(swap.Swap, Accept)
+	assert.NoError(t, swapResponse.Sign(receiver))
+	countersignedSwap := *swapResponse.record
+	countersignedSwap.Countersignature = swapResponse.Countersignature
+	assert.NoError(t, countersignedSwap.Verify())
+
+	countersignedSwap.Countersignature = swap.Swap.Signature
+	assert.EqualError(t, countersignedSwap.Verify(), utils.ErrInvalidCountersignature.Error())
+}
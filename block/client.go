@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package block
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+// AverageBlockTime is the block interval the chain difficulty aims for
+const AverageBlockTime = 2 * time.Minute
+
+var ErrNoBlocks = errors.New("no blocks")
+
+func Get(number uint64) (*Block, error) {
+	return get(strconv.FormatUint(number, 10))
+}
+
+func GetByHash(hash string) (*Block, error) {
+	return get(url.PathEscape(hash))
+}
+
+func get(numberOrHash string) (*Block, error) {
+	client := sdk.GetAPIClient()
+
+	req, err := client.NewRequest("GET", "/v3/blocks/"+numberOrHash, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Block *Block `json:"block"`
+	}
+	if err := client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Block, nil
+}
+
+// Current returns the latest block
+func Current() (*Block, error) {
+	blocks, err := List(NewQueryParamsBuilder().To(utils.Earlier).Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, ErrNoBlocks
+	}
+	return blocks[0], nil
+}
+
+// EstimateBlockTime averages the interval of the latest blocks, at most 100;
+// it is AverageBlockTime when there are too few blocks to tell
+func EstimateBlockTime(samples int) (time.Duration, error) {
+	if samples < 2 || samples > 100 {
+		samples = 100
+	}
+	blocks, err := List(NewQueryParamsBuilder().To(utils.Earlier).Limit(samples))
+	if err != nil {
+		return 0, err
+	}
+	return AverageInterval(blocks), nil
+}
+
+// AverageInterval is the mean time between the first and the last of blocks
+// listed newest first; it is AverageBlockTime when there are too few blocks to tell
+func AverageInterval(blocks []*Block) time.Duration {
+	if len(blocks) < 2 {
+		return AverageBlockTime
+	}
+
+	newest, oldest := blocks[0], blocks[len(blocks)-1]
+	if newest.Number <= oldest.Number {
+		return AverageBlockTime
+	}
+	span := newest.CreatedAt.Sub(oldest.CreatedAt)
+	if span <= 0 {
+		return AverageBlockTime
+	}
+	return span / time.Duration(newest.Number-oldest.Number)
+}
+
+func List(builder *QueryParamsBuilder) ([]*Block, error) {
+	params, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	client := sdk.GetAPIClient()
+	req, err := client.NewRequest("GET", "/v3/blocks?"+params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Blocks []*Block `json:"blocks"`
+	}
+	if err := client.Do(req, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Blocks, nil
+}
+
+type QueryParamsBuilder struct {
+	params url.Values
+	err    error
+}
+
+func NewQueryParamsBuilder() *QueryParamsBuilder {
+	return &QueryParamsBuilder{params: url.Values{}}
+}
+
+func (qb *QueryParamsBuilder) Limit(size int) *QueryParamsBuilder {
+	if size > 100 {
+		qb.err = errors.New("invalid size: max = 100")
+	}
+	qb.params.Set("limit", strconv.Itoa(size))
+	return qb
+}
+
+func (qb *QueryParamsBuilder) At(at uint64) *QueryParamsBuilder {
+	qb.params.Set("at", strconv.FormatUint(at, 10))
+	return qb
+}
+
+func (qb *QueryParamsBuilder) To(direction utils.Direction) *QueryParamsBuilder {
+	if direction != "" && (direction != utils.Later && direction != utils.Earlier) {
+		qb.err = errors.New("it must be 'later' or 'earlier'")
+	}
+	qb.params.Set("to", string(direction))
+	return qb
+}
+
+func (qb *QueryParamsBuilder) Build() (string, error) {
+	if qb.err != nil {
+		return "", qb.err
+	}
+	return qb.params.Encode(), nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+const testBlockHash = "00000000000001a1d2c0bd2c5e5ff3c0d7e2c5b3e8ce1a6e4f4e3e7f8b2a5c1d"
+
+var genesis = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newBlocksServer serves blocks 1 to height, one every interval
+func newBlocksServer(t *testing.T, height uint64, interval time.Duration) *httptest.Server {
+	makeBlock := func(n uint64) *Block {
+		return &Block{Number: n, Hash: fmt.Sprintf("%064x", n), CreatedAt: genesis.Add(time.Duration(n) * interval)}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/blocks":
+			q := r.URL.Query()
+			assert.Equal(t, "earlier", q.Get("to"))
+			limit, _ := strconv.Atoi(q.Get("limit"))
+			blocks := make([]*Block, 0)
+			for n := height; n > 0 && len(blocks) < limit; n-- {
+				blocks = append(blocks, makeBlock(n))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"blocks": blocks})
+		case "/v3/blocks/42":
+			json.NewEncoder(w).Encode(map[string]interface{}{"block": makeBlock(42)})
+		case "/v3/blocks/" + testBlockHash:
+			json.NewEncoder(w).Encode(map[string]interface{}{"block": &Block{Number: 7, Hash: testBlockHash}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(w, `{"code":2000,"message":"not found"}`)
+		}
+	}))
+
+	sdk.Init(&sdk.Config{
+		HTTPClient: ts.Client(),
+		Network:    sdk.Testnet,
+	})
+	sdk.GetAPIClient().URLAuthority = ts.URL
+	return ts
+}
+
+func TestGetBlock(t *testing.T) {
+	ts := newBlocksServer(t, 500, time.Minute)
+	defer ts.Close()
+
+	b, err := Get(42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), b.Number)
+	assert.Equal(t, genesis.Add(42*time.Minute), b.CreatedAt)
+
+	b, err = GetByHash(testBlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), b.Number)
+
+	_, err = Get(43)
+	assert.IsType(t, &sdk.APIError{}, err)
+}
+
+func TestCurrentBlock(t *testing.T) {
+	ts := newBlocksServer(t, 500, time.Minute)
+	defer ts.Close()
+
+	b, err := Current()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), b.Number)
+
+	blockTime, err := EstimateBlockTime(10)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, blockTime)
+}
+
+func TestEstimateBlockTimeFewBlocks(t *testing.T) {
+	ts := newBlocksServer(t, 1, time.Minute)
+	defer ts.Close()
+
+	blockTime, err := EstimateBlockTime(100)
+	assert.NoError(t, err)
+	assert.Equal(t, AverageBlockTime, blockTime)
+}
+
+func TestCurrentBlockEmpty(t *testing.T) {
+	ts := newBlocksServer(t, 0, time.Minute)
+	defer ts.Close()
+
+	_, err := Current()
+	assert.Equal(t, ErrNoBlocks, err)
+}
+
+func TestQueryParamsBuilder(t *testing.T) {
+	params, err := NewQueryParamsBuilder().At(10).To(utils.Later).Limit(5).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "at=10&limit=5&to=later", params)
+
+	_, err = NewQueryParamsBuilder().Limit(101).Build()
+	assert.Error(t, err)
+	_, err = NewQueryParamsBuilder().To(utils.Direction("up")).Build()
+	assert.Error(t, err)
+}
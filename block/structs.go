@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package block
+
+import "time"
+
+type Block struct {
+	Number    uint64    `json:"number"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
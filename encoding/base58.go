@@ -5,73 +5,149 @@
 package encoding
 
 import (
-	"math/big"
-	"strings"
+	"errors"
+	"fmt"
 )
 
 const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 
-var bigRadix = big.NewInt(58)
-var bigZero = big.NewInt(0)
+// inputs up to this size are converted using a stack buffer
+const base58ScratchSize = 128
 
+var decodeMap [256]byte
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = 0xff
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeMap[alphabet[i]] = byte(i)
+	}
+}
+
+var ErrEmptyBase58 = errors.New("empty base58 string")
+
+// Base58Error reports an invalid character in a base58 string
+type Base58Error struct {
+	Position int
+	Char     byte
+}
+
+func (e *Base58Error) Error() string {
+	return fmt.Sprintf("invalid base58 character %q at position %d", e.Char, e.Position)
+}
+
+// FromBase58 returns an empty slice if the input is not valid base58,
+// use DecodeBase58 to learn why
 func FromBase58(b string) []byte {
-	answer := big.NewInt(0)
-	j := big.NewInt(1)
+	val, err := DecodeBase58(b)
+	if err != nil {
+		return []byte("")
+	}
+	return val
+}
 
-	for i := len(b) - 1; i >= 0; i-- {
-		tmp := strings.IndexAny(alphabet, string(b[i]))
-		if tmp == -1 {
-			return []byte("")
-		}
-		idx := big.NewInt(int64(tmp))
-		tmp1 := big.NewInt(0)
-		tmp1.Mul(j, idx)
+func ToBase58(b []byte) string {
+	return string(AppendBase58(make([]byte, 0, len(b)*138/100+1), b))
+}
 
-		answer.Add(answer, tmp1)
-		j.Mul(j, bigRadix)
+// AppendBase58 appends the base58 encoding of src to dst; it does not
+// allocate when dst has enough capacity and src is at most 128 bytes
+func AppendBase58(dst, src []byte) []byte {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
 	}
 
-	tmpval := answer.Bytes()
+	// log(256) / log(58), rounded up
+	size := (len(src)-zeros)*138/100 + 1
+
+	var scratch [base58ScratchSize]byte
+	var buf []byte
+	if size <= len(scratch) {
+		buf = scratch[:size]
+	} else {
+		buf = make([]byte, size)
+	}
 
-	var numZeros int
-loop:
-	for numZeros = 0; numZeros < len(b); numZeros++ {
-		if b[numZeros] != alphabet[0] {
-			break loop
+	high := size - 1
+	for _, c := range src[zeros:] {
+		carry := uint32(c)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += uint32(buf[j]) << 8
+			buf[j] = byte(carry % 58)
+			carry /= 58
 		}
+		high = j
 	}
-	flen := numZeros + len(tmpval)
-	val := make([]byte, flen)
-	copy(val[numZeros:], tmpval)
 
-	return val
+	i := 0
+	for i < size && buf[i] == 0 {
+		i++
+	}
+
+	for n := 0; n < zeros; n++ {
+		dst = append(dst, alphabet[0])
+	}
+	for ; i < size; i++ {
+		dst = append(dst, alphabet[buf[i]])
+	}
+	return dst
 }
 
-func ToBase58(b []byte) string {
-	x := new(big.Int)
-	x.SetBytes(b)
-
-	answer := make([]byte, 0, len(b)*136/100)
-	for x.Cmp(bigZero) > 0 {
-		mod := new(big.Int)
-		x.DivMod(x, bigRadix, mod)
-		answer = append(answer, alphabet[mod.Int64()])
+// DecodeBase58 decodes a base58 string, returning a *Base58Error for the first invalid character
+func DecodeBase58(s string) ([]byte, error) {
+	return AppendDecodedBase58(nil, s)
+}
+
+// AppendDecodedBase58 appends the decoded bytes of s to dst; it does not
+// allocate when dst has enough capacity and s is at most 174 characters
+func AppendDecodedBase58(dst []byte, s string) ([]byte, error) {
+	if len(s) == 0 {
+		return dst, ErrEmptyBase58
 	}
 
-	// leading zero bytes
-loop:
-	for _, i := range b {
-		if i != 0 {
-			break loop
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	// log(58) / log(256), rounded up
+	size := (len(s)-zeros)*733/1000 + 1
+
+	var scratch [base58ScratchSize]byte
+	var buf []byte
+	if size <= len(scratch) {
+		buf = scratch[:size]
+	} else {
+		buf = make([]byte, size)
+	}
+
+	high := size - 1
+	for i := zeros; i < len(s); i++ {
+		digit := decodeMap[s[i]]
+		if digit == 0xff {
+			return dst, &Base58Error{Position: i, Char: s[i]}
+		}
+
+		carry := uint32(digit)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += uint32(buf[j]) * 58
+			buf[j] = byte(carry)
+			carry >>= 8
 		}
-		answer = append(answer, alphabet[0])
+		high = j
 	}
 
-	// reverse
-	alen := len(answer)
-	for i := 0; i < alen/2; i++ {
-		answer[i], answer[alen-1-i] = answer[alen-1-i], answer[i]
+	i := 0
+	for i < size && buf[i] == 0 {
+		i++
 	}
 
-	return string(answer)
+	for n := 0; n < zeros; n++ {
+		dst = append(dst, 0)
+	}
+	return append(dst, buf[i:]...), nil
 }
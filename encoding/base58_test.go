@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package encoding
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const accountNumber = "eZpG6Wi9SQvpDatEP7QGrx6nvzwd6s6R8DgMKgDbDY1R5bjzb9"
+
+var base58Vectors = []struct {
+	hex     string
+	encoded string
+}{
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"48656c6c6f20576f726c6421", "2NEpo7TZRRrLZSi2U"},
+	{"00000000000000000000", "1111111111"},
+	{"000000287fb4cd", "111233QC4"},
+	{"572e4794", "3EFU7m"},
+	{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+	{"10c8511e", "Rt5zm"},
+}
+
+// bigToBase58 is the previous math/big implementation, kept as a reference
+func bigToBase58(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	answer := make([]byte, 0)
+	for x.Sign() > 0 {
+		mod := new(big.Int)
+		x.DivMod(x, radix, mod)
+		answer = append([]byte{alphabet[mod.Int64()]}, answer...)
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		answer = append([]byte{alphabet[0]}, answer...)
+	}
+	return string(answer)
+}
+
+func TestBase58Vectors(t *testing.T) {
+	for _, v := range base58Vectors {
+		b, _ := hex.DecodeString(v.hex)
+		assert.Equal(t, v.encoded, ToBase58(b))
+
+		decoded, err := DecodeBase58(v.encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, b, decoded)
+		assert.Equal(t, b, FromBase58(v.encoded))
+	}
+}
+
+func TestBase58MatchesReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, r.Intn(200)+1)
+		r.Read(b)
+		for j := 0; j < r.Intn(4) && j < len(b); j++ {
+			b[j] = 0
+		}
+
+		encoded := ToBase58(b)
+		assert.Equal(t, bigToBase58(b), encoded)
+
+		decoded, err := DecodeBase58(encoded)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(b, decoded))
+	}
+}
+
+func TestDecodeBase58Invalid(t *testing.T) {
+	_, err := DecodeBase58("")
+	assert.EqualError(t, err, ErrEmptyBase58.Error())
+
+	_, err = DecodeBase58("abc0def")
+	assert.Equal(t, &Base58Error{Position: 3, Char: '0'}, err)
+	assert.EqualError(t, err, `invalid base58 character '0' at position 3`)
+
+	_, err = DecodeBase58("11I")
+	assert.Equal(t, &Base58Error{Position: 2, Char: 'I'}, err)
+
+	assert.Equal(t, []byte(""), FromBase58("IOl"))
+}
+
+func TestBase58Check(t *testing.T) {
+	payload, err := FromBase58Check(accountNumber, SHA3Checksum)
+	assert.NoError(t, err)
+	assert.Len(t, payload, 33)
+	assert.Equal(t, accountNumber, ToBase58Check(payload, SHA3Checksum))
+
+	tampered := []byte(accountNumber)
+	tampered[10] = 'a'
+	_, err = FromBase58Check(string(tampered), SHA3Checksum)
+	assert.EqualError(t, err, ErrInvalidChecksum.Error())
+
+	_, err = FromBase58Check("2g", SHA3Checksum)
+	assert.EqualError(t, err, ErrInvalidChecksum.Error())
+}
+
+func TestBase58NoAllocs(t *testing.T) {
+	b := FromBase58(accountNumber)
+	dst := make([]byte, 0, 64)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		AppendBase58(dst[:0], b)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	allocs = testing.AllocsPerRun(100, func() {
+		AppendDecodedBase58(dst[:0], accountNumber)
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestBase58CheckNoCopy(t *testing.T) {
+	payload, err := FromBase58Check(accountNumber, SHA3Checksum)
+	assert.NoError(t, err)
+
+	// nothing beyond the checksum and the encoded string is allocated, the
+	// payload is not copied to the heap
+	allocs := testing.AllocsPerRun(100, func() {
+		ToBase58Check(payload, SHA3Checksum)
+	})
+	expected := testing.AllocsPerRun(100, func() {
+		SHA3Checksum(payload)
+		ToBase58(payload)
+	})
+	assert.Equal(t, expected, allocs)
+}
+
+func BenchmarkToBase58(b *testing.B) {
+	data := FromBase58(accountNumber)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToBase58(data)
+	}
+}
+
+func BenchmarkAppendBase58(b *testing.B) {
+	data := FromBase58(accountNumber)
+	dst := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AppendBase58(dst[:0], data)
+	}
+}
+
+func BenchmarkBigToBase58(b *testing.B) {
+	data := FromBase58(accountNumber)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bigToBase58(data)
+	}
+}
+
+func BenchmarkDecodeBase58(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DecodeBase58(accountNumber)
+	}
+}
+
+func BenchmarkFromBase58Check(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBase58Check(accountNumber, SHA3Checksum)
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package encoding
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const ChecksumLength = 4
+
+var ErrInvalidChecksum = errors.New("invalid checksum")
+
+// ChecksumFunc computes the checksum appended to a payload before base58 encoding
+type ChecksumFunc func(payload []byte) [ChecksumLength]byte
+
+// SHA3Checksum is the first four bytes of SHA3-256, as used by seeds and account numbers
+func SHA3Checksum(payload []byte) [ChecksumLength]byte {
+	var checksum [ChecksumLength]byte
+	digest := sha3.Sum256(payload)
+	copy(checksum[:], digest[:ChecksumLength])
+	return checksum
+}
+
+// ToBase58Check encodes the payload followed by its checksum. The payload may
+// be secret, e.g. a seed, so it is joined to the checksum in a scratch buffer
+// which is zeroed before returning.
+func ToBase58Check(payload []byte, checksum ChecksumFunc) string {
+	sum := checksum(payload)
+
+	var scratch [base58ScratchSize]byte
+	var b []byte
+	if len(payload)+ChecksumLength <= len(scratch) {
+		b = scratch[:0]
+	} else {
+		b = make([]byte, 0, len(payload)+ChecksumLength)
+	}
+	defer func() {
+		for i := range b {
+			b[i] = 0
+		}
+	}()
+
+	b = append(b, payload...)
+	b = append(b, sum[:]...)
+	return ToBase58(b)
+}
+
+// FromBase58Check decodes s and verifies the trailing checksum, returning the payload only
+func FromBase58Check(s string, checksum ChecksumFunc) ([]byte, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < ChecksumLength {
+		return nil, ErrInvalidChecksum
+	}
+
+	payload := b[:len(b)-ChecksumLength]
+	expected := checksum(payload)
+	if subtle.ConstantTimeCompare(expected[:], b[len(payload):]) != 1 {
+		return nil, ErrInvalidChecksum
+	}
+	return payload, nil
+}
@@ -4,6 +4,8 @@
 // license that can be found in the LICENSE file.
 package encoding
 
+import "errors"
+
 const varint64MaximumBytes = 9
 
 func ToVarint64(value uint64) []byte {
@@ -22,3 +24,44 @@ func ToVarint64(value uint64) []byte {
 	}
 	return result
 }
+
+var (
+	ErrVarint64Truncated = errors.New("varint64 truncated")
+	ErrVarint64Overflow  = errors.New("varint64 exceeds the allowed maximum")
+)
+
+// FromVarint64 decodes a varint from the start of buffer and returns the value
+// and the number of bytes read. The ninth byte carries a full eight bits, so
+// any nine byte sequence fits in 64 bits.
+func FromVarint64(buffer []byte) (uint64, int, error) {
+	result := uint64(0)
+	shift := uint(0)
+
+	for count := 0; count < len(buffer); count++ {
+		b := uint64(buffer[count])
+		if count == varint64MaximumBytes-1 {
+			return result | b<<shift, count + 1, nil
+		}
+
+		result |= (b & 0x7f) << shift
+		if b&0x80 == 0 {
+			return result, count + 1, nil
+		}
+		shift += 7
+	}
+
+	return 0, 0, ErrVarint64Truncated
+}
+
+// FromVarint64WithMaximum is FromVarint64 which also rejects values greater than maximum,
+// e.g. for length prefixes that must not exceed the remaining buffer
+func FromVarint64WithMaximum(buffer []byte, maximum uint64) (uint64, int, error) {
+	value, n, err := FromVarint64(buffer)
+	if err != nil {
+		return 0, 0, err
+	}
+	if value > maximum {
+		return 0, 0, ErrVarint64Overflow
+	}
+	return value, n, nil
+}
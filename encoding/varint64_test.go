@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromVarint64(t *testing.T) {
+	for _, v := range []uint64{0, 1, 0x7f, 0x80, 0x3fff, 0x4000, 1 << 56, 1<<63 + 12345, ^uint64(0)} {
+		b := ToVarint64(v)
+		value, n, err := FromVarint64(append(b, 0xff))
+		assert.NoError(t, err)
+		assert.Equal(t, v, value)
+		assert.Equal(t, len(b), n)
+	}
+
+	_, _, err := FromVarint64([]byte{0x80, 0x80})
+	assert.EqualError(t, err, ErrVarint64Truncated.Error())
+
+	_, _, err = FromVarint64(nil)
+	assert.EqualError(t, err, ErrVarint64Truncated.Error())
+
+	_, _, err = FromVarint64WithMaximum(ToVarint64(300), 299)
+	assert.EqualError(t, err, ErrVarint64Overflow.Error())
+}
@@ -3,7 +3,6 @@ module github.com/bitmark-inc/bitmark-sdk-go
 go 1.14
 
 require (
-	github.com/bitmark-inc/bitmarkd v0.13.2
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/crypto v0.17.0
 	golang.org/x/text v0.14.0
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package paperwallet generates printable backups of an account and checks
+// that a transcribed backup recovers the same account
+package paperwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/text/language"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/qrcode"
+)
+
+const checksumLength = 4
+
+var (
+	ErrNullAccount      = errors.New("account is null")
+	ErrChecksumMismatch = errors.New("checksum does not match the recovery phrase")
+	ErrAccountMismatch  = errors.New("recovery phrase does not recover the printed account number")
+	ErrDestroyed        = errors.New("paper wallet destroyed")
+)
+
+// PaperWallet holds everything printed on a backup sheet
+type PaperWallet struct {
+	AccountNumber  string
+	Network        sdk.Network
+	Language       language.Tag
+	RecoveryPhrase []string
+	Checksum       string
+
+	accountQR *qrcode.QRCode
+	seedQR    *qrcode.QRCode
+}
+
+// Generate builds a paper wallet for the account with the recovery phrase in the given language
+func Generate(acct account.Account, lang language.Tag) (*PaperWallet, error) {
+	if acct == nil {
+		return nil, ErrNullAccount
+	}
+
+	phrase, err := acct.RecoveryPhrase(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	accountQR, err := qrcode.Encode(acct.AccountNumber(), qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+
+	seedQR, err := qrcode.Encode(acct.Seed(), qrcode.High)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaperWallet{
+		AccountNumber:  acct.AccountNumber(),
+		Network:        acct.Network(),
+		Language:       lang,
+		RecoveryPhrase: phrase,
+		Checksum:       Checksum(phrase),
+		accountQR:      accountQR,
+		seedQR:         seedQR,
+	}, nil
+}
+
+// Checksum returns a short code over the recovery phrase so a transcription can be checked by eye,
+// formatted as two groups of four hex digits
+func Checksum(phrase []string) string {
+	digest := sha3.Sum256([]byte(strings.Join(phrase, " ")))
+	s := strings.ToUpper(hex.EncodeToString(digest[:checksumLength]))
+	return s[:4] + "-" + s[4:]
+}
+
+// Verify checks a transcribed sheet: the checksum must match the phrase and the phrase
+// must recover the printed account number
+func Verify(accountNumber string, phrase []string, lang language.Tag, checksum string) error {
+	if !strings.EqualFold(strings.TrimSpace(checksum), Checksum(phrase)) {
+		return ErrChecksumMismatch
+	}
+
+	acct, err := account.FromRecoveryPhrase(phrase, lang)
+	if err != nil {
+		return err
+	}
+	defer acct.Destroy()
+
+	if acct.AccountNumber() != accountNumber {
+		return ErrAccountMismatch
+	}
+	return nil
+}
+
+// Destroy wipes the recovery phrase and the seed QR code once the sheet has been printed
+func (w *PaperWallet) Destroy() {
+	for i := range w.RecoveryPhrase {
+		w.RecoveryPhrase[i] = ""
+	}
+	w.RecoveryPhrase = nil
+	w.seedQR = nil
+}
+
+// SVG renders the sheet as an A4 sized SVG document
+func (w *PaperWallet) SVG() (string, error) {
+	return w.render(svgTemplate)
+}
+
+// HTML renders the sheet as a standalone HTML page with the QR codes inlined as SVG
+func (w *PaperWallet) HTML() (string, error) {
+	return w.render(htmlTemplate)
+}
+
+type qrView struct {
+	Dimension int
+	Path      string
+}
+
+type sheetView struct {
+	*PaperWallet
+	AccountQR qrView
+	SeedQR    qrView
+	Words     []wordView
+}
+
+type wordView struct {
+	Index int
+	Word  string
+	X, Y  int
+}
+
+func (w *PaperWallet) render(tmpl *template.Template) (string, error) {
+	if w.seedQR == nil || len(w.RecoveryPhrase) == 0 {
+		return "", ErrDestroyed
+	}
+
+	view := sheetView{
+		PaperWallet: w,
+		AccountQR:   qrView{w.accountQR.Dimension(), w.accountQR.Path()},
+		SeedQR:      qrView{w.seedQR.Dimension(), w.seedQR.Path()},
+	}
+	// three columns of words
+	rows := (len(w.RecoveryPhrase) + 2) / 3
+	for i, word := range w.RecoveryPhrase {
+		view.Words = append(view.Words, wordView{
+			Index: i + 1,
+			Word:  word,
+			X:     20 + (i/rows)*60,
+			Y:     150 + (i%rows)*9,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"lang": func(t language.Tag) string { return t.String() },
+	"upper": func(n sdk.Network) string {
+		return strings.ToUpper(string(n))
+	},
+	"scale": func(size, dim int) string {
+		return fmt.Sprintf("%.4f", float64(size)/float64(dim))
+	},
+}
+
+const svgBody = `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="210mm" height="297mm" viewBox="0 0 210 297" font-family="monospace">
+<rect width="210" height="297" fill="#ffffff"/>
+<text x="20" y="20" font-size="8" font-weight="bold">Bitmark account backup ({{upper .Network}})</text>
+<text x="20" y="32" font-size="4">Account number</text>
+<text x="20" y="38" font-size="3.4">{{.AccountNumber}}</text>
+<g transform="translate(20,44) scale({{scale 60 .AccountQR.Dimension}})" shape-rendering="crispEdges"><path d="{{.AccountQR.Path}}" fill="#000000"/></g>
+<text x="100" y="50" font-size="4">Seed (keep secret)</text>
+<g transform="translate(100,54) scale({{scale 60 .SeedQR.Dimension}})" shape-rendering="crispEdges"><path d="{{.SeedQR.Path}}" fill="#000000"/></g>
+<text x="20" y="138" font-size="4">Recovery phrase ({{lang .Language}})</text>
+{{range .Words}}<text x="{{.X}}" y="{{.Y}}" font-size="4">{{.Index}}. {{.Word}}</text>
+{{end}}<text x="20" y="280" font-size="4">Checksum: {{.Checksum}}</text>
+</svg>`
+
+var (
+	svgTemplate  = template.Must(template.New("svg").Funcs(funcs).Parse(svgBody))
+	htmlTemplate = template.Must(template.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Bitmark account backup</title>
+<style>
+body { font-family: monospace; margin: 2em; }
+.qr { display: inline-block; margin-right: 2em; vertical-align: top; }
+.qr svg { width: 50mm; height: 50mm; }
+ol { column-count: 3; font-size: 1.2em; }
+@media print { .noprint { display: none; } }
+</style>
+</head>
+<body>
+<h1>Bitmark account backup ({{upper .Network}})</h1>
+<h2>Account number</h2>
+<p>{{.AccountNumber}}</p>
+<div class="qr"><p>Account number</p><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 {{.AccountQR.Dimension}} {{.AccountQR.Dimension}}" shape-rendering="crispEdges"><rect width="100%" height="100%" fill="#ffffff"/><path d="{{.AccountQR.Path}}" fill="#000000"/></svg></div>
+<div class="qr"><p>Seed (keep secret)</p><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 {{.SeedQR.Dimension}} {{.SeedQR.Dimension}}" shape-rendering="crispEdges"><rect width="100%" height="100%" fill="#ffffff"/><path d="{{.SeedQR.Path}}" fill="#000000"/></svg></div>
+<h2>Recovery phrase ({{lang .Language}})</h2>
+<ol>
+{{range .Words}}<li>{{.Word}}</li>
+{{end}}</ol>
+<h2>Checksum</h2>
+<p>{{.Checksum}}</p>
+</body>
+</html>`))
+)
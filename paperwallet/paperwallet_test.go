@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package paperwallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+const (
+	seed          = "9J87CAsHdFdoEu6N1unZk3sqhVBkVL8Z8"
+	accountNumber = "eMCcmw1SKoohNUf3LeioTFKaYNYfp2bzFYpjm3EddwxBSWYVCb"
+	phrase        = "箱 阻 起 歸 徹 矮 問 栽 瓜 鼓 支 樂 制"
+)
+
+func TestGenerate(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	acct, err := account.FromSeed(seed)
+	assert.NoError(t, err)
+
+	w, err := Generate(acct, language.TraditionalChinese)
+	assert.NoError(t, err)
+	assert.Equal(t, accountNumber, w.AccountNumber)
+	assert.Equal(t, phrase, strings.Join(w.RecoveryPhrase, " "))
+	assert.Equal(t, Checksum(w.RecoveryPhrase), w.Checksum)
+	assert.Len(t, w.Checksum, 9)
+
+	svg, err := w.SVG()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, accountNumber)
+	assert.Contains(t, svg, w.Checksum)
+	for _, word := range w.RecoveryPhrase {
+		assert.Contains(t, svg, word)
+	}
+
+	html, err := w.HTML()
+	assert.NoError(t, err)
+	assert.Contains(t, html, accountNumber)
+	assert.Contains(t, html, "<li>箱</li>")
+
+	w.Destroy()
+	assert.Empty(t, w.RecoveryPhrase)
+	_, err = w.SVG()
+	assert.EqualError(t, err, ErrDestroyed.Error())
+
+	_, err = Generate(nil, language.AmericanEnglish)
+	assert.EqualError(t, err, ErrNullAccount.Error())
+}
+
+func TestVerify(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	words := strings.Split(phrase, " ")
+	checksum := Checksum(words)
+
+	assert.NoError(t, Verify(accountNumber, words, language.TraditionalChinese, checksum))
+	assert.NoError(t, Verify(accountNumber, words, language.TraditionalChinese, strings.ToLower(checksum)))
+
+	assert.EqualError(t, Verify(accountNumber, words, language.TraditionalChinese, "0000-0000"), ErrChecksumMismatch.Error())
+
+	swapped := append([]string{}, words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	assert.EqualError(t, Verify(accountNumber, swapped, language.TraditionalChinese, checksum), ErrChecksumMismatch.Error())
+
+	other := "eZpG6Wi9SQvpDatEP7QGrx6nvzwd6s6R8DgMKgDbDY1R5bjzb9"
+	assert.EqualError(t, Verify(other, words, language.TraditionalChinese, checksum), ErrAccountMismatch.Error())
+}
@@ -0,0 +1,498 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package qrcode is a small QR code generator which encodes content in byte
+// mode and renders the symbol as an image, PNG or SVG
+package qrcode
+
+import (
+	"errors"
+)
+
+type Level int
+
+const (
+	Low      Level = iota // recovers ~7% of codewords
+	Medium                // recovers ~15% of codewords
+	Quartile              // recovers ~25% of codewords
+	High                  // recovers ~30% of codewords
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+var (
+	ErrInvalidLevel   = errors.New("invalid error correction level")
+	ErrDataTooLong    = errors.New("data too long to fit in a QR code")
+	ErrInvalidVersion = errors.New("invalid version")
+)
+
+// format bits for each level, in the order of the Level constants
+var levelFormatBits = [...]int{1, 0, 3, 2}
+
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var numErrorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// QRCode is an encoded symbol; Modules[y][x] is true for a dark module
+type QRCode struct {
+	Version int
+	Level   Level
+	Mask    int
+	Size    int
+	Modules [][]bool
+
+	isFunction [][]bool
+}
+
+// Encode returns the smallest QR code which holds the content in byte mode at the given level
+func Encode(content string, level Level) (*QRCode, error) {
+	if level < Low || level > High {
+		return nil, ErrInvalidLevel
+	}
+
+	data := []byte(content)
+	version := minVersion
+	for ; version <= maxVersion; version++ {
+		if 4+charCountBits(version)+len(data)*8 <= numDataCodewords(version, level)*8 {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrDataTooLong
+	}
+
+	return encodeVersion(data, version, level)
+}
+
+func encodeVersion(data []byte, version int, level Level) (*QRCode, error) {
+	if version < minVersion || version > maxVersion {
+		return nil, ErrInvalidVersion
+	}
+
+	capacity := numDataCodewords(version, level) * 8
+	bb := &bitBuffer{}
+	bb.append(0x4, 4) // byte mode
+	bb.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	if bb.len() > capacity {
+		return nil, ErrDataTooLong
+	}
+
+	// terminator, byte alignment and pad bytes
+	terminator := capacity - bb.len()
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	bb.append(0, (8-bb.len()%8)%8)
+	for pad := 0xec; bb.len() < capacity; pad ^= 0xec ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	q := newQRCode(version, level)
+	q.drawFunctionPatterns()
+	q.drawCodewords(addErrorCorrection(bb.bytes(), version, level))
+
+	// choose the mask with the lowest penalty
+	bestMask, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		penalty := q.penalty()
+		if minPenalty < 0 || penalty < minPenalty {
+			bestMask, minPenalty = mask, penalty
+		}
+		q.applyMask(mask) // masking is an xor so this undoes it
+	}
+	q.Mask = bestMask
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+	q.isFunction = nil
+
+	return q, nil
+}
+
+func newQRCode(version int, level Level) *QRCode {
+	size := version*4 + 17
+	q := &QRCode{
+		Version:    version,
+		Level:      level,
+		Size:       size,
+		Modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for i := 0; i < size; i++ {
+		q.Modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+	return q
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// numRawDataModules is the number of modules left for data and ECC after the function patterns
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func numDataCodewords(version int, level Level) int {
+	return numRawDataModules(version)/8 -
+		eccCodewordsPerBlock[level][version]*numErrorCorrectionBlocks[level][version]
+}
+
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	numAlign := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	}
+
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+10; i > 0; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+func (q *QRCode) setFunctionModule(x, y int, dark bool) {
+	q.Modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *QRCode) drawFunctionPatterns() {
+	// timing patterns
+	for i := 0; i < q.Size; i++ {
+		q.setFunctionModule(6, i, i%2 == 0)
+		q.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	// finder patterns, overwriting part of the timing patterns
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.Size-4, 3)
+	q.drawFinderPattern(3, q.Size-4)
+
+	// alignment patterns, skipping the three finder corners
+	positions := alignmentPatternPositions(q.Version)
+	n := len(positions)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(positions[i], positions[j])
+		}
+	}
+
+	// reserve the format areas; the real bits are drawn after masking
+	q.drawFormatBits(0)
+	q.drawVersion()
+}
+
+func (q *QRCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.Size || yy < 0 || yy >= q.Size {
+				continue
+			}
+			dist := abs(dx)
+			if abs(dy) > dist {
+				dist = abs(dy)
+			}
+			q.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *QRCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			dist := abs(dx)
+			if abs(dy) > dist {
+				dist = abs(dy)
+			}
+			q.setFunctionModule(x+dx, y+dy, dist != 1)
+		}
+	}
+}
+
+// formatBits returns the 15 bit BCH protected format information
+func formatBits(level Level, mask int) int {
+	data := levelFormatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionBits returns the 18 bit BCH protected version information
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+	}
+	return version<<12 | rem
+}
+
+func (q *QRCode) drawFormatBits(mask int) {
+	bits := formatBits(q.Level, mask)
+
+	// first copy around the top left finder
+	for i := 0; i <= 5; i++ {
+		q.setFunctionModule(8, i, bit(bits, i))
+	}
+	q.setFunctionModule(8, 7, bit(bits, 6))
+	q.setFunctionModule(8, 8, bit(bits, 7))
+	q.setFunctionModule(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunctionModule(14-i, 8, bit(bits, i))
+	}
+
+	// second copy split between the other two finders
+	for i := 0; i < 8; i++ {
+		q.setFunctionModule(q.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunctionModule(8, q.Size-15+i, bit(bits, i))
+	}
+	q.setFunctionModule(8, q.Size-8, true) // always dark
+}
+
+func (q *QRCode) drawVersion() {
+	if q.Version < 7 {
+		return
+	}
+
+	bits := versionBits(q.Version)
+	for i := 0; i < 18; i++ {
+		a := q.Size - 11 + i%3
+		b := i / 3
+		q.setFunctionModule(a, b, bit(bits, i))
+		q.setFunctionModule(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the data in the zig-zag order, two columns at a time from the bottom right
+func (q *QRCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = q.Size - 1 - vert
+				}
+				if q.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				q.Modules[y][x] = bit(int(codewords[i>>3]), 7-i&7)
+				i++
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.Modules[y][x] = !q.Modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol with the four rules of ISO/IEC 18004 section 7.8.3
+func (q *QRCode) penalty() int {
+	const (
+		n1 = 3
+		n2 = 3
+		n3 = 40
+		n4 = 10
+	)
+
+	result := 0
+	finderLike := func(line []bool) int {
+		count := 0
+		pattern := []bool{true, false, true, true, true, false, true}
+		for i := 0; i+7 <= len(line); i++ {
+			match := true
+			for k := 0; k < 7; k++ {
+				if line[i+k] != pattern[k] {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			before := i >= 4 && !line[i-1] && !line[i-2] && !line[i-3] && !line[i-4]
+			after := i+11 <= len(line) && !line[i+7] && !line[i+8] && !line[i+9] && !line[i+10]
+			if i < 4 {
+				before = true
+				for k := 0; k < i; k++ {
+					before = before && !line[k]
+				}
+			}
+			if i+11 > len(line) {
+				after = true
+				for k := i + 7; k < len(line); k++ {
+					after = after && !line[k]
+				}
+			}
+			if before || after {
+				count++
+			}
+		}
+		return count
+	}
+	runs := func(line []bool) int {
+		score := 0
+		runLen := 1
+		for i := 1; i <= len(line); i++ {
+			if i < len(line) && line[i] == line[i-1] {
+				runLen++
+				continue
+			}
+			if runLen >= 5 {
+				score += n1 + runLen - 5
+			}
+			runLen = 1
+		}
+		return score
+	}
+
+	column := make([]bool, q.Size)
+	for i := 0; i < q.Size; i++ {
+		for j := 0; j < q.Size; j++ {
+			column[j] = q.Modules[j][i]
+		}
+		result += runs(q.Modules[i]) + runs(column)
+		result += (finderLike(q.Modules[i]) + finderLike(column)) * n3
+	}
+
+	// 2x2 blocks of the same colour
+	for y := 0; y < q.Size-1; y++ {
+		for x := 0; x < q.Size-1; x++ {
+			c := q.Modules[y][x]
+			if c == q.Modules[y][x+1] && c == q.Modules[y+1][x] && c == q.Modules[y+1][x+1] {
+				result += n2
+			}
+		}
+	}
+
+	// balance of dark modules
+	dark := 0
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.Modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := q.Size * q.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	if k > 0 {
+		result += k * n4
+	}
+
+	return result
+}
+
+func bit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		b.bits = append(b.bits, bit(value, i))
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	result := make([]byte, (len(b.bits)+7)/8)
+	for i, v := range b.bits {
+		if v {
+			result[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+	return result
+}
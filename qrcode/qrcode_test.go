@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at version 1-M, from ISO/IEC 18004 annex I
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := rsRemainder(data, rsDivisor(10))
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, 0x5412, formatBits(Medium, 0))
+	assert.Equal(t, 0x77c4, formatBits(Low, 0))
+	assert.Equal(t, 0x07c94, versionBits(7))
+	assert.Equal(t, 0x28c69, versionBits(40))
+}
+
+func TestCapacity(t *testing.T) {
+	for level, capacity := range map[Level]int{Low: 17, Medium: 14, Quartile: 11, High: 7} {
+		q, err := Encode(strings.Repeat("a", capacity), level)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, q.Version)
+		assert.Equal(t, 21, q.Size)
+
+		q, err = Encode(strings.Repeat("a", capacity+1), level)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, q.Version)
+	}
+
+	q, err := Encode(strings.Repeat("a", 2953), Low)
+	assert.NoError(t, err)
+	assert.Equal(t, 40, q.Version)
+
+	_, err = Encode(strings.Repeat("a", 2954), Low)
+	assert.EqualError(t, err, ErrDataTooLong.Error())
+
+	_, err = Encode("a", Level(4))
+	assert.EqualError(t, err, ErrInvalidLevel.Error())
+}
+
+// readCodewords undoes the mask and reads the codewords back in placement order
+func readCodewords(q *QRCode) []byte {
+	ref := newQRCode(q.Version, q.Level)
+	ref.drawFunctionPatterns()
+
+	var bits bitBuffer
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vert
+				}
+				if ref.isFunction[y][x] {
+					continue
+				}
+				ref.Modules[y][x] = q.Modules[y][x]
+			}
+		}
+	}
+	ref.applyMask(q.Mask)
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vert
+				}
+				if !ref.isFunction[y][x] {
+					if ref.Modules[y][x] {
+						bits.append(1, 1)
+					} else {
+						bits.append(0, 1)
+					}
+				}
+			}
+		}
+	}
+	return bits.bytes()[:numRawDataModules(q.Version)/8]
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	content := "bitmark:eZpG6Wi9SQvpDatEP7QGrx6nvzwd6s6R8DgMKgDbDY1R5bjzb9?network=testnet"
+	for _, level := range []Level{Low, Medium, Quartile, High} {
+		q, err := Encode(content, level)
+		assert.NoError(t, err)
+
+		// format bits read back from around the top left finder
+		bits := 0
+		for i := 0; i <= 5; i++ {
+			if q.Modules[i][8] {
+				bits |= 1 << uint(i)
+			}
+		}
+		assert.Equal(t, formatBits(level, q.Mask)&0x3f, bits)
+
+		codewords := readCodewords(q)
+		// only single block symbols keep the data codewords contiguous
+		if numErrorCorrectionBlocks[level][q.Version] == 1 {
+			data := codewords[:numDataCodewords(q.Version, level)]
+			assert.Equal(t, byte(0x40|len(content)>>4), data[0])
+			assert.Equal(t, content, string(decodeByteMode(data, len(content))))
+		}
+		assert.Equal(t, numRawDataModules(q.Version)/8, len(codewords))
+	}
+}
+
+func decodeByteMode(data []byte, n int) []byte {
+	result := make([]byte, n)
+	for i := 0; i < n; i++ {
+		// skip the 4 bit mode and 8 bit count
+		result[i] = data[i+1]<<4 | data[i+2]>>4
+	}
+	return result
+}
+
+func TestRender(t *testing.T) {
+	q, err := Encode("hello world", Medium)
+	assert.NoError(t, err)
+
+	b, err := q.PNG(4)
+	assert.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, (21+2*quietZone)*4, img.Bounds().Dx())
+
+	// top left module of the finder pattern is dark
+	r, _, _, _ := img.At(quietZone*4, quietZone*4).RGBA()
+	assert.Equal(t, uint32(0), r)
+
+	svg := q.SVG(4)
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, `width="116"`)
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// quietZone is the light border around the symbol required by the spec, in modules
+const quietZone = 4
+
+// Image renders the symbol with each module scaled to moduleSize pixels
+func (q *QRCode) Image(moduleSize int) image.Image {
+	if moduleSize < 1 {
+		moduleSize = 1
+	}
+
+	dim := (q.Size + quietZone*2) * moduleSize
+	img := image.NewPaletted(image.Rect(0, 0, dim, dim), color.Palette{color.White, color.Black})
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.Modules[y][x] {
+				continue
+			}
+			px := (x + quietZone) * moduleSize
+			py := (y + quietZone) * moduleSize
+			for dy := 0; dy < moduleSize; dy++ {
+				for dx := 0; dx < moduleSize; dx++ {
+					img.SetColorIndex(px+dx, py+dy, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG returns the symbol encoded as a PNG image
+func (q *QRCode) PNG(moduleSize int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, q.Image(moduleSize)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG returns the symbol as a standalone SVG document
+func (q *QRCode) SVG(moduleSize int) string {
+	if moduleSize < 1 {
+		moduleSize = 1
+	}
+
+	dim := q.Dimension()
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#ffffff"/><path d="%s" fill="#000000"/></svg>`,
+		dim*moduleSize, dim*moduleSize, dim, dim, q.Path())
+}
+
+// Path returns the dark modules as SVG path data in module units, offset by the quiet zone,
+// for embedding the symbol in a larger document
+func (q *QRCode) Path() string {
+	var path strings.Builder
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.Modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	return path.String()
+}
+
+// Dimension is the width of the symbol including the quiet zone, in modules
+func (q *QRCode) Dimension() int {
+	return q.Size + quietZone*2
+}
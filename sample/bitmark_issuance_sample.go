@@ -5,6 +5,8 @@
 package main
 
 import (
+	"context"
+
 	"github.com/bitmark-inc/bitmark-sdk-go/account"
 	"github.com/bitmark-inc/bitmark-sdk-go/bitmark"
 )
@@ -17,3 +19,22 @@ func issueBitmarks(issuer account.Account, assetID string, quantity int) ([]stri
 
 	return bitmarkIDs, err
 }
+
+// issueBitmarksInBatches issues more than 100 bitmarks, 100 per request
+func issueBitmarksInBatches(issuer account.Account, assetID string, quantity int) ([]string, error) {
+	issuanceParams, err := bitmark.NewIssuanceParams(assetID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := bitmark.NewIssuancePlan(issuanceParams, &bitmark.IssuancePlanOptions{Workers: 4, Retries: 2})
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.Sign(issuer); err != nil {
+		return nil, err
+	}
+
+	err = plan.Submit(context.Background())
+	return plan.BitmarkIDs(), err
+}
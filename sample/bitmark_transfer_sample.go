@@ -38,27 +38,23 @@ func sendTransferOffer(sender account.Account, bitmarkID string, receiverAccount
 func respondToTransferOffer(receiver account.Account, bitmarkID string, confirmation bitmark.OfferResponseAction) error {
 	bmk, _ := bitmark.Get(bitmarkID)
 
-	if bmk != nil && bmk.Status != "offering" {
+	if bmk == nil || bmk.Status != "offering" {
 		return errors.New("bitmark is not offering")
 	}
 
-	rp := bitmark.NewTransferResponseParams(bmk, confirmation)
-	rp.Sign(receiver)
-
-	_, err := bitmark.Respond(rp)
-	return err
+	if confirmation == bitmark.Accept {
+		_, err := bmk.Offer.Accept(receiver)
+		return err
+	}
+	return bmk.Offer.Reject(receiver)
 }
 
 func cancelTransferOffer(sender account.Account, bitmarkID string) error {
 	bmk, _ := bitmark.Get(bitmarkID)
 
-	if bmk != nil && bmk.Status != "offering" {
+	if bmk == nil || bmk.Status != "offering" {
 		return errors.New("bitmark is not offering")
 	}
 
-	rp := bitmark.NewTransferResponseParams(bmk, "cancel")
-	rp.Sign(sender)
-
-	_, err := bitmark.Respond(rp)
-	return err
+	return bmk.Offer.Cancel(sender)
 }
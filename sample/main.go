@@ -79,7 +79,7 @@ func main() {
 	*/
 
 	assetID = "YOUR_ASSET_ID"
-	quantity := 100 // Number of bitmarks you want to issue, quantity must be less than or equal 100.
+	quantity := 100 // Number of bitmarks you want to issue, quantity must be less than or equal 100, use issueBitmarksInBatches for more.
 
 	bitmarkIDs, err := issueBitmarks(acc, assetID, quantity)
 
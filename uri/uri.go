@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package uri encodes account numbers, bitmark IDs and transfer offers as
+// bitmark:<account>?network=...&bitmark=...&offer=... so they can be passed
+// between devices, e.g. as a QR code
+package uri
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/qrcode"
+)
+
+const Scheme = "bitmark"
+
+const bitmarkIDLength = 32
+
+var (
+	ErrInvalidScheme    = errors.New("invalid scheme")
+	ErrInvalidNetwork   = errors.New("invalid network")
+	ErrInvalidBitmarkID = errors.New("invalid bitmark id")
+	ErrMissingAccount   = errors.New("account number is missing")
+)
+
+type URI struct {
+	AccountNumber string
+	Network       sdk.Network
+	BitmarkID     string
+	OfferID       string
+}
+
+// New returns a URI for the account on the current network
+func New(accountNumber string) (*URI, error) {
+	if err := account.ValidateAccountNumber(accountNumber); err != nil {
+		return nil, err
+	}
+
+	return &URI{
+		AccountNumber: accountNumber,
+		Network:       sdk.GetNetwork(),
+	}, nil
+}
+
+// Bitmark attaches a bitmark ID to the URI
+func (u *URI) Bitmark(bitmarkID string) *URI {
+	u.BitmarkID = bitmarkID
+	return u
+}
+
+// Offer attaches a transfer offer ID to the URI
+func (u *URI) Offer(offerID string) *URI {
+	u.OfferID = offerID
+	return u
+}
+
+// Parse decodes and validates a bitmark URI
+func Parse(s string) (*URI, error) {
+	parsed, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(parsed.Scheme, Scheme) {
+		return nil, ErrInvalidScheme
+	}
+
+	// accept both bitmark:<account> and bitmark://<account>
+	accountNumber := parsed.Opaque
+	if accountNumber == "" {
+		accountNumber = parsed.Host
+	}
+	if accountNumber == "" {
+		return nil, ErrMissingAccount
+	}
+
+	u := &URI{
+		AccountNumber: accountNumber,
+		Network:       sdk.GetNetwork(),
+	}
+
+	query := parsed.Query()
+	if network := query.Get("network"); network != "" {
+		u.Network = sdk.Network(network)
+	}
+	u.BitmarkID = query.Get("bitmark")
+	u.OfferID = query.Get("offer")
+
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Validate checks the account number against the current network and the format of the bitmark ID
+func (u *URI) Validate() error {
+	if u.AccountNumber == "" {
+		return ErrMissingAccount
+	}
+
+	switch u.Network {
+	case sdk.Livenet, sdk.Testnet:
+	default:
+		return ErrInvalidNetwork
+	}
+	if u.Network != sdk.GetNetwork() {
+		return account.ErrWrongNetwork
+	}
+
+	if err := account.ValidateAccountNumber(u.AccountNumber); err != nil {
+		return err
+	}
+
+	if u.BitmarkID != "" {
+		b, err := hex.DecodeString(u.BitmarkID)
+		if err != nil || len(b) != bitmarkIDLength {
+			return ErrInvalidBitmarkID
+		}
+	}
+
+	return nil
+}
+
+// String encodes the URI; query parameters are always in the same order
+func (u *URI) String() string {
+	vals := url.Values{}
+	vals.Set("network", string(u.Network))
+	if u.BitmarkID != "" {
+		vals.Set("bitmark", u.BitmarkID)
+	}
+	if u.OfferID != "" {
+		vals.Set("offer", u.OfferID)
+	}
+
+	return Scheme + ":" + u.AccountNumber + "?" + vals.Encode()
+}
+
+// QRCode encodes the URI as a QR code ready to be rendered as PNG or SVG
+func (u *URI) QRCode(level qrcode.Level) (*qrcode.QRCode, error) {
+	return qrcode.Encode(u.String(), level)
+}
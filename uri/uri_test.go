@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package uri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/qrcode"
+)
+
+const (
+	testnetAccountNumber = "eZpG6Wi9SQvpDatEP7QGrx6nvzwd6s6R8DgMKgDbDY1R5bjzb9"
+	livenetAccountNumber = "bqSUHTVRYnrUPBEU48riv9UwDmdRnHm9Mf9LWYuYEa7JKtqgKw"
+	bitmarkID            = "fa9bb80247dd0f6b3e3f21153f49fbb297b9568e67e298c96dbd75d3a348efeb"
+	offerID              = "d205ed72-792f-43ca-885a-737949be6501"
+)
+
+func TestEncodeAndParse(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	u, err := New(testnetAccountNumber)
+	assert.NoError(t, err)
+	u.Bitmark(bitmarkID).Offer(offerID)
+
+	s := u.String()
+	assert.Equal(t, "bitmark:"+testnetAccountNumber+"?bitmark="+bitmarkID+"&network=testnet&offer="+offerID, s)
+
+	parsed, err := Parse(s)
+	assert.NoError(t, err)
+	assert.Equal(t, u, parsed)
+
+	parsed, err = Parse("bitmark://" + testnetAccountNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, &URI{AccountNumber: testnetAccountNumber, Network: sdk.Testnet}, parsed)
+}
+
+func TestParseInvalid(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	_, err := Parse("ethereum:" + testnetAccountNumber)
+	assert.EqualError(t, err, ErrInvalidScheme.Error())
+
+	_, err = Parse("bitmark:")
+	assert.EqualError(t, err, ErrMissingAccount.Error())
+
+	_, err = Parse("bitmark:" + testnetAccountNumber + "?network=devnet")
+	assert.EqualError(t, err, ErrInvalidNetwork.Error())
+
+	_, err = Parse("bitmark:" + testnetAccountNumber + "?network=livenet")
+	assert.EqualError(t, err, account.ErrWrongNetwork.Error())
+
+	_, err = Parse("bitmark:" + livenetAccountNumber)
+	assert.EqualError(t, err, account.ErrWrongNetwork.Error())
+
+	_, err = Parse("bitmark:" + testnetAccountNumber + "?bitmark=abc")
+	assert.EqualError(t, err, ErrInvalidBitmarkID.Error())
+}
+
+func TestQRCode(t *testing.T) {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	u, err := New(testnetAccountNumber)
+	assert.NoError(t, err)
+
+	q, err := u.Bitmark(bitmarkID).QRCode(qrcode.Medium)
+	assert.NoError(t, err)
+	assert.Equal(t, q.Version*4+17, q.Size)
+
+	b, err := q.PNG(2)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, b)
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package utils_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/asset"
+	"github.com/bitmark-inc/bitmark-sdk-go/bitmark"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+func newRecord(tag uint64) utils.Packable {
+	switch tag {
+	case utils.RegisterTag:
+		return &asset.RegistrationParams{}
+	case utils.IssueTag:
+		return &bitmark.IssueRequest{}
+	case utils.DirectTransferTag:
+		return &bitmark.TransferRequest{}
+	case utils.CountersignedTransferTag:
+		return &bitmark.CountersignedTransferRequest{}
+	case utils.ShareTag:
+		return &bitmark.ShareRequest{}
+	case utils.ShareGrantTag:
+		return &bitmark.GrantRequest{}
+	case utils.ShareSwapTag:
+		return &bitmark.SwapRequest{}
+	}
+	return nil
+}
+
+// anything Unpack accepts must pack again, and unpack to the same record;
+// varints are not required to be minimal, so the bytes may differ
+func FuzzUnpack(f *testing.F) {
+	for _, g := range goldenRecords {
+		b, _ := hex.DecodeString(g.expected)
+		f.Add(b)
+	}
+	f.Add([]byte{0x08, 0x20})
+	f.Add([]byte{0x09, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tag, err := utils.RecordTag(data)
+		if err != nil {
+			return
+		}
+		record := newRecord(tag)
+		if record == nil {
+			return
+		}
+		if err := utils.Unpack(data, record); err != nil {
+			return
+		}
+
+		packed, err := utils.Pack(record)
+		if err != nil {
+			t.Fatalf("pack of unpacked record failed: %s", err)
+		}
+		again := newRecord(tag)
+		if err := utils.Unpack(packed, again); err != nil {
+			t.Fatalf("unpack of packed record failed: %s", err)
+		}
+		repacked, err := utils.Pack(again)
+		if err != nil {
+			t.Fatalf("pack of repacked record failed: %s", err)
+		}
+		if !bytes.Equal(packed, repacked) {
+			t.Fatalf("round trip mismatch:\n input:    %x\n packed:   %x\n repacked: %x", data, packed, repacked)
+		}
+	})
+}
+
+func FuzzPackShare(f *testing.F) {
+	f.Add([]byte(goldenLink), uint64(12345))
+	f.Add([]byte{}, uint64(0))
+
+	f.Fuzz(func(t *testing.T, link []byte, quantity uint64) {
+		share := &bitmark.ShareRequest{Link: hex.EncodeToString(link), Quantity: quantity}
+		packed, err := utils.Pack(share)
+		if len(link) != 32 {
+			if err == nil {
+				t.Fatalf("link of %d bytes packed", len(link))
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var actual bitmark.ShareRequest
+		if err := utils.Unpack(packed, &actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual != *share {
+			t.Fatalf("round trip mismatch: %+v != %+v", actual, *share)
+		}
+	})
+}
@@ -7,22 +7,20 @@ package utils
 import (
 	"encoding/hex"
 	"fmt"
-	"reflect"
 
 	"github.com/bitmark-inc/bitmark-sdk-go/account"
 	"github.com/bitmark-inc/bitmark-sdk-go/encoding"
 )
 
+// record tags as defined by bitmarkd's transactionrecord package
 const (
-	tagName = "pack"
-
-	tagRegister              = uint64(2)
-	tagIssue                 = uint64(3)
-	tagDirectTransfer        = uint64(4)
-	tagCountersignedTransfer = uint64(5)
-	tagShare                 = uint64(8)
-	tagShareGrant            = uint64(9)
-	tagShareSwap             = uint64(10)
+	RegisterTag              = uint64(2)
+	IssueTag                 = uint64(3)
+	DirectTransferTag        = uint64(4)
+	CountersignedTransferTag = uint64(5)
+	ShareTag                 = uint64(8)
+	ShareGrantTag            = uint64(9)
+	ShareSwapTag             = uint64(10)
 )
 
 type Direction string
@@ -32,67 +30,114 @@ const (
 	Later   = Direction("later")
 )
 
-func Pack(params interface{}) ([]byte, error) {
-	var buffer []byte
-
-	v := reflect.ValueOf(params).Elem()
-	switch v.Type().String() {
-	case "asset.RegistrationParams":
-		buffer = encoding.ToVarint64(tagRegister)
-	case "bitmark.IssueRequest":
-		buffer = encoding.ToVarint64(tagIssue)
-	case "bitmark.TransferRequest":
-		requireCountersigned := v.Field(v.NumField() - 1).Bool()
-		buffer = encoding.ToVarint64(tagDirectTransfer)
-		if requireCountersigned {
-			buffer = encoding.ToVarint64(tagCountersignedTransfer)
+type fieldKind int
+
+const (
+	fieldUTF8 fieldKind = iota
+	fieldHex32
+	fieldHex64
+	fieldAccount
+	fieldPayment
+	fieldUint64
+)
+
+// Field is a single packed field bound to the struct member holding its value
+type Field struct {
+	name string
+	kind fieldKind
+	str  *string
+	num  *uint64
+}
+
+// UTF8Field is a length prefixed string
+func UTF8Field(name string, value *string) Field {
+	return Field{name: name, kind: fieldUTF8, str: value}
+}
+
+// Hex32Field is a hex encoded 32 byte value, e.g. a tx id
+func Hex32Field(name string, value *string) Field {
+	return Field{name: name, kind: fieldHex32, str: value}
+}
+
+// Hex64Field is a hex encoded 64 byte value, e.g. an asset id or a signature
+func Hex64Field(name string, value *string) Field {
+	return Field{name: name, kind: fieldHex64, str: value}
+}
+
+// AccountField is a base58 account number, packed without its checksum
+func AccountField(name string, value *string) Field {
+	return Field{name: name, kind: fieldAccount, str: value}
+}
+
+// PaymentField is the optional escrow payment of a transfer
+func PaymentField(name string) Field {
+	return Field{name: name, kind: fieldPayment}
+}
+
+// Uint64Field is a varint encoded integer
+func Uint64Field(name string, value *uint64) Field {
+	return Field{name: name, kind: fieldUint64, num: value}
+}
+
+// Packable is a transaction record which can be packed into the binary
+// message signed by its owner
+type Packable interface {
+	// RecordTag is the bitmarkd transaction record tag
+	RecordTag() uint64
+	// PackFields lists the fields in the order bitmarkd packs them, signature excluded
+	PackFields() []Field
+}
+
+// Signed is a Packable whose signatures bitmarkd appends after the packed fields,
+// the countersignature of countersigned records is the only one listed here
+// since the signature is already one of its packed fields
+type Signed interface {
+	Packable
+	SignatureFields() []Field
+}
+
+func Pack(record Packable) ([]byte, error) {
+	buffer := encoding.ToVarint64(record.RecordTag())
+	for _, field := range record.PackFields() {
+		var err error
+		buffer, err = field.appendTo(buffer)
+		if err != nil {
+			return nil, err
 		}
-	case "bitmark.CountersignedTransferRequest":
-		buffer = encoding.ToVarint64(tagCountersignedTransfer)
-	case "bitmark.ShareRequest":
-		buffer = encoding.ToVarint64(tagShare)
-	case "bitmark.GrantRequest", "bitmark.CountersignedGrantRequest":
-		buffer = encoding.ToVarint64(tagShareGrant)
-	case "bitmark.SwapRequest", "bitmark.CountersignedSwapRequest":
-		buffer = encoding.ToVarint64(tagShareSwap)
 	}
 
-	for i := 0; i < v.NumField(); i++ {
-		tag := v.Type().Field(i).Tag.Get(tagName)
-		switch tag {
-		case "utf8":
-			value := v.Field(i).String()
-			buffer = appendString(buffer, value)
-		case "hex64":
-			value := v.Field(i).String()
-			bytes, err := hex.DecodeString(value)
-			if err != nil || len(bytes) != 64 {
-				return nil, fmt.Errorf("invalid %s", v.Type().Field(i).Name)
-			}
-			buffer = appendBytes(buffer, bytes)
-		case "hex32":
-			value := v.Field(i).String()
-			bytes, err := hex.DecodeString(value)
-			if err != nil || len(bytes) != 32 {
-				return nil, fmt.Errorf("invalid %s", v.Type().Field(i).Name)
-			}
-			buffer = appendBytes(buffer, bytes)
-		case "account":
-			value := v.Field(i).String()
-			bytes := encoding.FromBase58(value)
-			if len(bytes) != account.Base58AccountNumberLength {
-				return nil, fmt.Errorf("invalid %s", v.Type().Field(i).Name)
-			}
-			buffer = appendBytes(buffer, bytes[:len(bytes)-account.ChecksumLength])
-		case "payment": // TODO: support escrow
-			buffer = append(buffer, 0)
-		case "uint64":
-			value := v.Field(i).Uint()
-			buffer = appendUint64(buffer, value)
+	return buffer, nil
+}
+
+func (f Field) appendTo(buffer []byte) ([]byte, error) {
+	switch f.kind {
+	case fieldUTF8:
+		return appendString(buffer, *f.str), nil
+	case fieldHex32, fieldHex64:
+		bytes, err := hex.DecodeString(*f.str)
+		if err != nil || len(bytes) != f.size() {
+			return nil, fmt.Errorf("invalid %s", f.name)
+		}
+		return appendBytes(buffer, bytes), nil
+	case fieldAccount:
+		bytes, err := encoding.DecodeBase58(*f.str)
+		if err != nil || len(bytes) != account.Base58AccountNumberLength {
+			return nil, fmt.Errorf("invalid %s", f.name)
 		}
+		return appendBytes(buffer, bytes[:len(bytes)-account.ChecksumLength]), nil
+	case fieldPayment: // TODO: support escrow
+		return append(buffer, 0), nil
+	case fieldUint64:
+		return appendUint64(buffer, *f.num), nil
 	}
+	return nil, fmt.Errorf("invalid %s", f.name)
+}
 
-	return buffer, nil
+func (f Field) size() int {
+	if f.kind == fieldHex32 {
+		return 32
+	}
+	return 64
 }
 
 func appendString(buffer []byte, s string) []byte {
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/asset"
+	"github.com/bitmark-inc/bitmark-sdk-go/bitmark"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+// accounts made from the test keys of bitmarkd's transactionrecord package
+const (
+	registrantAccount = "es6cC2aq7jxKT1pzjdwfi3Q8LLvyaQNWUgJwo1TdaSZMX4Ybv8"
+	issuerAccount     = "f9WQMtFnXeZKASkp8tGdZTWEFYmuV3yFaE44BYJ84jNxXfUaKi"
+	ownerOneAccount   = "eEVYCy1tGqbjXcNHYwsv45N31zm8NzHJdH9NULNUkPqaFkquKF"
+	ownerTwoAccount   = "fA9Hk518mJNozbmag3AgQ49QGNm1AM52n4x7zB9R9XtzZxZcqA"
+
+	goldenLink    = "79a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b06084"
+	goldenShareID = "630c041cd1f586bcb9097e816189185c1e0379f67bbfc2f0626724f542047873"
+)
+
+// unsigned messages from the pack tests of bitmarkd v0.13.2 transactionrecord
+var goldenRecords = []struct {
+	name     string
+	record   utils.Packable
+	expected string
+}{
+	{
+		name: "asset",
+		record: &asset.RegistrationParams{
+			Name:        "Item's Name",
+			Fingerprint: "0123456789abcdef",
+			Metadata:    "description\x00Just the description",
+			Registrant:  registrantAccount,
+		},
+		expected: "020b4974656d2773204e616d651030313233343536373839616263646566206465736372697074696f6e004a7573742074" +
+			"6865206465736372697074696f6e21137a8192565e6ca23580e18159ef3073f6e2fb8e7e9d31497e79d7731ba3741101",
+	},
+	{
+		name: "issue",
+		record: &bitmark.IssueRequest{
+			AssetID: "59d06155d25dffdb982729de8dce9d7855ca094d8bab8124b347c40668477056b3c27ccb7d71b54043d207ccd187642bf9c8466f9a8d0dbefb4c41633a7e39ef",
+			Owner:   issuerAccount,
+			Nonce:   99,
+		},
+		expected: "034059d06155d25dffdb982729de8dce9d7855ca094d8bab8124b347c40668477056b3c27ccb7d71b54043d207ccd187642bf9c8466f9a8d0dbefb4c416" +
+			"33a7e39ef21139fc486a2534f17e36707fa4b953e3b3400e2729f656116dd7b018df34698bdc263",
+	},
+	{
+		name:   "transfer",
+		record: &bitmark.TransferRequest{Link: goldenLink, Owner: ownerOneAccount},
+		expected: "042079a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b0608400211327640e4aab92d87b4a6a2f30b881f4" +
+			"4929f866043a841c3814b166b88944b092",
+	},
+	{
+		name:     "share",
+		record:   &bitmark.ShareRequest{Link: goldenLink, Quantity: 12345},
+		expected: "082079a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b06084b960",
+	},
+	{
+		name: "grant",
+		record: &bitmark.GrantRequest{
+			ShareID:   goldenShareID,
+			Quantity:  100,
+			Owner:     ownerOneAccount,
+			Recipient: ownerTwoAccount,
+		},
+		expected: "0920630c041cd1f586bcb9097e816189185c1e0379f67bbfc2f0626724f54204787364211327640e4aab92d87b4a6a2f30b881f44929f8660" +
+			"43a841c3814b166b88944b0922113a13632d5425aed3a6b62e2bb6de4c9594841c15b701569ec9999dc201c35f7b300",
+	},
+	{
+		name: "swap",
+		record: &bitmark.SwapRequest{
+			ShareIDOne:  goldenShareID,
+			QuantityOne: 129,
+			OwnerOne:    ownerOneAccount,
+			ShareIDTwo:  goldenLink,
+			QuantityTwo: 215,
+			OwnerTwo:    ownerTwoAccount,
+		},
+		expected: "0a20630c041cd1f586bcb9097e816189185c1e0379f67bbfc2f0626724f5420478738101211327640e4aab92d87b4a6a2f30b881f44929f8660" +
+			"43a841c3814b166b88944b0922079a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b06084d7012113a13632d5425aed3a6b62e2" +
+			"bb6de4c9594841c15b701569ec9999dc201c35f7b300",
+	},
+}
+
+func TestPackGolden(t *testing.T) {
+	for _, g := range goldenRecords {
+		packed, err := utils.Pack(g.record)
+		assert.NoError(t, err, g.name)
+		assert.Equal(t, g.expected, hex.EncodeToString(packed), g.name)
+	}
+}
+
+// the countersigned records pack the owner's signature, which is what bitmarkd
+// appends to the unsigned message before the countersignature is made
+func TestPackCountersignedGolden(t *testing.T) {
+	signature := strings.Repeat("5a", 64)
+
+	offer, err := bitmark.NewOfferParams(ownerOneAccount, nil)
+	assert.NoError(t, err)
+	offer.FromLatestTx(goldenLink)
+	unsigned, err := utils.Pack(offer.Offer.Transfer)
+	assert.NoError(t, err)
+	assert.Equal(t, "052079a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b0608400211327640e4aab92d87b4a6a2f30b881f4"+
+		"4929f866043a841c3814b166b88944b092", hex.EncodeToString(unsigned))
+
+	transfer := &bitmark.CountersignedTransferRequest{Link: goldenLink, Owner: ownerOneAccount, Signature: signature}
+	packed, err := utils.Pack(transfer)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(unsigned)+"40"+signature, hex.EncodeToString(packed))
+
+	grant := goldenRecords[4].record.(*bitmark.GrantRequest)
+	countersignedGrant := &bitmark.CountersignedGrantRequest{
+		ShareID:     grant.ShareID,
+		Quantity:    grant.Quantity,
+		Owner:       grant.Owner,
+		Recipient:   grant.Recipient,
+		BeforeBlock: grant.BeforeBlock,
+		Signature:   signature,
+	}
+	packed, err = utils.Pack(countersignedGrant)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenRecords[4].expected+"40"+signature, hex.EncodeToString(packed))
+
+	swap := goldenRecords[5].record.(*bitmark.SwapRequest)
+	countersignedSwap := &bitmark.CountersignedSwapRequest{
+		ShareIDOne:  swap.ShareIDOne,
+		QuantityOne: swap.QuantityOne,
+		OwnerOne:    swap.OwnerOne,
+		ShareIDTwo:  swap.ShareIDTwo,
+		QuantityTwo: swap.QuantityTwo,
+		OwnerTwo:    swap.OwnerTwo,
+		BeforeBlock: swap.BeforeBlock,
+		Signature:   signature,
+	}
+	packed, err = utils.Pack(countersignedSwap)
+	assert.NoError(t, err)
+	assert.Equal(t, goldenRecords[5].expected+"40"+signature, hex.EncodeToString(packed))
+}
+
+func TestPackInvalidField(t *testing.T) {
+	_, err := utils.Pack(&bitmark.ShareRequest{Link: "abcd", Quantity: 1})
+	assert.EqualError(t, err, "invalid Link")
+
+	_, err = utils.Pack(&bitmark.TransferRequest{Link: goldenLink, Owner: "0OIl"})
+	assert.EqualError(t, err, "invalid Owner")
+
+	_, err = utils.Pack(&bitmark.CountersignedTransferRequest{Link: goldenLink, Owner: ownerOneAccount, Signature: "00"})
+	assert.EqualError(t, err, "invalid Signature")
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils
+
+import (
+	"encoding/hex"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// PackSigned packs the record with its signatures appended, i.e. the
+// transaction as it is stored by bitmarkd
+func PackSigned(record Signed) ([]byte, error) {
+	buffer, err := Pack(record)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range record.SignatureFields() {
+		if *field.str == "" {
+			return nil, ErrMissingSignature
+		}
+		sig, err := hex.DecodeString(*field.str)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+		buffer = appendBytes(buffer, sig)
+	}
+
+	return buffer, nil
+}
+
+// TxID computes the transaction ID of a signed record, the SHA3-256 digest of the packed transaction
+func TxID(record Signed) (string, error) {
+	packed, err := PackSigned(record)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha3.Sum256(packed)
+	return hex.EncodeToString(digest[:]), nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils_test
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/asset"
+	"github.com/bitmark-inc/bitmark-sdk-go/bitmark"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+// private keys of the issuer and ownerOne accounts in bitmarkd's tests
+var (
+	issuerKey, _   = hex.DecodeString("f3f7a1fc331071c2b1cbbe4f3aee235aaeccd85d2a804c44b5c603b4ca4d9ec09fc486a2534f17e36707fa4b953e3b3400e2729f656116dd7b018df34698bdc2")
+	ownerOneKey, _ = hex.DecodeString("c7ae9f22320eda650289f2647bc3a44ffae05579cb6a422090b459b317edf4a127640e4aab92d87b4a6a2f30b881f44929f866043a841c3814b166b88944b092")
+)
+
+func signWith(t *testing.T, key []byte, record utils.Packable) string {
+	message, err := utils.Pack(record)
+	assert.NoError(t, err)
+	return hex.EncodeToString(ed25519.Sign(ed25519.PrivateKey(key), message))
+}
+
+// tx IDs from the pack tests of bitmarkd v0.13.2 transactionrecord
+func TestTxIDGolden(t *testing.T) {
+	registration := goldenRecords[0].record.(*asset.RegistrationParams)
+	assert.Equal(t, "59d06155d25dffdb982729de8dce9d7855ca094d8bab8124b347c40668477056b3c27ccb7d71b54043d207ccd187642bf9c8466f9a8d0dbefb4c41633a7e39ef", registration.AssetID())
+	// the issue golden record refers to the asset above
+	assert.Equal(t, registration.AssetID(), goldenRecords[1].record.(*bitmark.IssueRequest).AssetID)
+
+	issue := *goldenRecords[1].record.(*bitmark.IssueRequest)
+	_, err := issue.BitmarkID()
+	assert.EqualError(t, err, utils.ErrMissingSignature.Error())
+
+	issue.Signature = signWith(t, issuerKey, &issue)
+	assert.NoError(t, issue.Verify())
+	bitmarkID, err := issue.BitmarkID()
+	assert.NoError(t, err)
+	assert.Equal(t, "79a67be2b3d313bd490363fb0d27901c46ed53d3f7b21f60d48bc42439b06084", bitmarkID)
+
+	transfer := *goldenRecords[2].record.(*bitmark.TransferRequest)
+	transfer.Signature = signWith(t, issuerKey, &transfer)
+	txID, err := transfer.TxID()
+	assert.NoError(t, err)
+	assert.Equal(t, "630c041cd1f586bcb9097e816189185c1e0379f67bbfc2f0626724f542047873", txID)
+
+	offer, err := bitmark.NewOfferParams(ownerOneAccount, nil)
+	assert.NoError(t, err)
+	offer.FromLatestTx(goldenLink)
+	_, err = offer.Offer.Transfer.TxID()
+	assert.EqualError(t, err, utils.ErrMissingCountersignature.Error())
+
+	countersigned := &bitmark.CountersignedTransferRequest{
+		Link:      goldenLink,
+		Owner:     ownerOneAccount,
+		Signature: signWith(t, issuerKey, offer.Offer.Transfer),
+	}
+	countersigned.Countersignature = signWith(t, ownerOneKey, countersigned)
+	assert.NoError(t, countersigned.Verify(issuerAccount))
+	txID, err = countersigned.TxID()
+	assert.NoError(t, err)
+	assert.Equal(t, "6e72bb9a5850cc288e3c726fbbe433a0e08178acde1c8cb031a4362d77a04e09", txID)
+}
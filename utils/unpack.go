@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/encoding"
+)
+
+var (
+	ErrUnknownRecordTag   = errors.New("unknown record tag")
+	ErrRecordTagMismatch  = errors.New("record tag does not match the target type")
+	ErrTruncatedRecord    = errors.New("record truncated")
+	ErrTrailingData       = errors.New("unexpected data after record")
+	ErrUnsupportedEscrow  = errors.New("escrow payment not supported")
+	ErrInvalidUnpackParam = errors.New("unpack target must be a non-nil record pointer")
+)
+
+// RecordTag returns the tag at the start of a packed record
+func RecordTag(data []byte) (uint64, error) {
+	tag, _, err := encoding.FromVarint64(data)
+	if err != nil {
+		return 0, err
+	}
+	if tag < RegisterTag || tag > ShareSwapTag {
+		return 0, ErrUnknownRecordTag
+	}
+	return tag, nil
+}
+
+// Unpack decodes a packed record, as produced by Pack with the signature and
+// optional countersignature appended, into v. v must be the SDK record for the
+// record tag, e.g. *bitmark.CountersignedTransferRequest for tag 5.
+// A record without signatures unpacks with the signature fields left empty.
+func Unpack(data []byte, v Packable) error {
+	if v == nil {
+		return ErrInvalidUnpackParam
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidUnpackParam
+	}
+
+	tag, n, err := encoding.FromVarint64(data)
+	if err != nil {
+		return err
+	}
+	if tag < RegisterTag || tag > ShareSwapTag {
+		return ErrUnknownRecordTag
+	}
+	if tag != v.RecordTag() {
+		return ErrRecordTagMismatch
+	}
+
+	r := &recordReader{data: data, offset: n}
+	for _, field := range v.PackFields() {
+		if err := r.readField(field); err != nil {
+			return err
+		}
+	}
+
+	// signatures follow the packed fields
+	if signed, ok := v.(Signed); ok {
+		for _, field := range signed.SignatureFields() {
+			if r.remaining() == 0 {
+				break
+			}
+			b, err := r.readBytes()
+			if err != nil {
+				return fmt.Errorf("invalid %s: %s", field.name, err)
+			}
+			*field.str = hex.EncodeToString(b)
+		}
+	}
+
+	if r.remaining() != 0 {
+		return ErrTrailingData
+	}
+	return nil
+}
+
+type recordReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *recordReader) remaining() int {
+	return len(r.data) - r.offset
+}
+
+func (r *recordReader) readField(field Field) error {
+	switch field.kind {
+	case fieldUTF8:
+		b, err := r.readBytes()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", field.name, err)
+		}
+		*field.str = string(b)
+	case fieldHex32, fieldHex64:
+		b, err := r.readBytes()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", field.name, err)
+		}
+		if len(b) != field.size() {
+			return fmt.Errorf("invalid %s", field.name)
+		}
+		*field.str = hex.EncodeToString(b)
+	case fieldAccount:
+		b, err := r.readBytes()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", field.name, err)
+		}
+		if len(b)+account.ChecksumLength != account.Base58AccountNumberLength {
+			return fmt.Errorf("invalid %s", field.name)
+		}
+		*field.str = encoding.ToBase58Check(b, encoding.SHA3Checksum)
+	case fieldPayment:
+		flag, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		if flag != 0 {
+			return ErrUnsupportedEscrow
+		}
+	case fieldUint64:
+		u, err := r.readUint64()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", field.name, err)
+		}
+		*field.num = u
+	}
+	return nil
+}
+
+func (r *recordReader) readByte() (byte, error) {
+	if r.remaining() < 1 {
+		return 0, ErrTruncatedRecord
+	}
+	b := r.data[r.offset]
+	r.offset++
+	return b, nil
+}
+
+func (r *recordReader) readUint64() (uint64, error) {
+	value, n, err := encoding.FromVarint64(r.data[r.offset:])
+	if err != nil {
+		return 0, err
+	}
+	r.offset += n
+	return value, nil
+}
+
+func (r *recordReader) readBytes() ([]byte, error) {
+	length, n, err := encoding.FromVarint64WithMaximum(r.data[r.offset:], uint64(r.remaining()))
+	if err == encoding.ErrVarint64Overflow {
+		return nil, ErrTruncatedRecord
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.offset += n
+	if r.remaining() < int(length) {
+		return nil, ErrTruncatedRecord
+	}
+	b := r.data[r.offset : r.offset+int(length)]
+	r.offset += int(length)
+	return b, nil
+}
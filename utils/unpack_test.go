@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdk "github.com/bitmark-inc/bitmark-sdk-go"
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+	"github.com/bitmark-inc/bitmark-sdk-go/asset"
+	"github.com/bitmark-inc/bitmark-sdk-go/bitmark"
+	"github.com/bitmark-inc/bitmark-sdk-go/encoding"
+	"github.com/bitmark-inc/bitmark-sdk-go/utils"
+)
+
+var (
+	sender   account.Account
+	receiver account.Account
+)
+
+const (
+	assetID = "3c50d70e0fe78819e7755687003483523852ee6ecc59fe40a4e70e89496c4d45313c6d76141bc322ba56ad3f7cd9c906b951791208281ddba3ebb5e7ad83436c"
+	txID    = "67ef8bfee0ef7b8c33eda34ba21c8b2b0fbff601a7021984b2e27985251a0a80"
+)
+
+func init() {
+	sdk.Init(&sdk.Config{Network: sdk.Testnet})
+
+	sender, _ = account.FromSeed("5XEECttxvRBzxzAmuV4oh6T1FcQu4mBg8eWd9wKbf8hweXsfwtJ8sfH")
+	receiver, _ = account.FromSeed("5XEECt4yuMK4xqBLr9ky5FBWpkAR6VHNZSz8fUzZDXPnN3D9MeivTSA")
+}
+
+// packSigned appends the hex encoded signatures to the packed message the same way bitmarkd does
+func packSigned(t *testing.T, record utils.Packable, signatures ...string) []byte {
+	packed, err := utils.Pack(record)
+	assert.NoError(t, err)
+	for _, s := range signatures {
+		sig, err := hex.DecodeString(s)
+		assert.NoError(t, err)
+		packed = append(packed, encoding.ToVarint64(uint64(len(sig)))...)
+		packed = append(packed, sig...)
+	}
+	return packed
+}
+
+func TestUnpackRegistration(t *testing.T) {
+	params, err := asset.NewRegistrationParams("name", map[string]string{"k1": "v1"})
+	assert.NoError(t, err)
+	assert.NoError(t, params.SetFingerprintFromData([]byte("hello world")))
+	assert.NoError(t, params.Sign(sender))
+
+	packed := packSigned(t, params, params.Signature)
+	tag, err := utils.RecordTag(packed)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), tag)
+
+	var actual asset.RegistrationParams
+	assert.NoError(t, utils.Unpack(packed, &actual))
+	assert.Equal(t, *params, actual)
+
+	// unsigned message leaves the signature empty
+	unsigned, _ := utils.Pack(params)
+	actual = asset.RegistrationParams{}
+	assert.NoError(t, utils.Unpack(unsigned, &actual))
+	assert.Empty(t, actual.Signature)
+	assert.Equal(t, params.Fingerprint, actual.Fingerprint)
+}
+
+func TestUnpackIssue(t *testing.T) {
+	issue := &bitmark.IssueRequest{AssetID: assetID, Owner: sender.AccountNumber(), Nonce: 1<<40 + 7}
+	issue.Signature = hex.EncodeToString(sender.Sign(packSigned(t, issue)))
+
+	var actual bitmark.IssueRequest
+	assert.NoError(t, utils.Unpack(packSigned(t, issue, issue.Signature), &actual))
+	assert.Equal(t, *issue, actual)
+}
+
+func TestUnpackTransfers(t *testing.T) {
+	params, err := bitmark.NewTransferParams(receiver.AccountNumber())
+	assert.NoError(t, err)
+	params.FromLatestTx(txID)
+	assert.NoError(t, params.Sign(sender))
+
+	packed := packSigned(t, params.Transfer, params.Transfer.Signature)
+	var transfer bitmark.TransferRequest
+	assert.NoError(t, utils.Unpack(packed, &transfer))
+	assert.Equal(t, params.Transfer.Link, transfer.Link)
+	assert.Equal(t, params.Transfer.Owner, transfer.Owner)
+	assert.Equal(t, params.Transfer.Signature, transfer.Signature)
+
+	var countersigned bitmark.CountersignedTransferRequest
+	assert.EqualError(t, utils.Unpack(packed, &countersigned), utils.ErrRecordTagMismatch.Error())
+
+	offer, err := bitmark.NewOfferParams(receiver.AccountNumber(), nil)
+	assert.NoError(t, err)
+	offer.FromLatestTx(txID)
+	assert.NoError(t, offer.Sign(sender))
+
+	record := &bitmark.CountersignedTransferRequest{
+		Link:      txID,
+		Owner:     receiver.AccountNumber(),
+		Signature: offer.Offer.Transfer.Signature,
+	}
+	record.Countersignature = hex.EncodeToString(receiver.Sign(packSigned(t, record)))
+
+	assert.NoError(t, utils.Unpack(packSigned(t, record, record.Countersignature), &countersigned))
+	assert.Equal(t, *record, countersigned)
+}
+
+func TestUnpackShares(t *testing.T) {
+	share := &bitmark.ShareRequest{Link: txID, Quantity: 100}
+	share.Signature = hex.EncodeToString(sender.Sign(packSigned(t, share)))
+	var actualShare bitmark.ShareRequest
+	assert.NoError(t, utils.Unpack(packSigned(t, share, share.Signature), &actualShare))
+	assert.Equal(t, *share, actualShare)
+
+	grant := &bitmark.CountersignedGrantRequest{
+		ShareID:     txID,
+		Quantity:    10,
+		Owner:       sender.AccountNumber(),
+		Recipient:   receiver.AccountNumber(),
+		BeforeBlock: 12345,
+	}
+	unsigned := bitmark.GrantRequest{
+		ShareID:     grant.ShareID,
+		Quantity:    grant.Quantity,
+		Owner:       grant.Owner,
+		Recipient:   grant.Recipient,
+		BeforeBlock: grant.BeforeBlock,
+	}
+	grant.Signature = hex.EncodeToString(sender.Sign(packSigned(t, &unsigned)))
+	grant.Countersignature = hex.EncodeToString(receiver.Sign(packSigned(t, grant)))
+	var actualGrant bitmark.CountersignedGrantRequest
+	assert.NoError(t, utils.Unpack(packSigned(t, grant, grant.Countersignature), &actualGrant))
+	assert.Equal(t, *grant, actualGrant)
+
+	swap := &bitmark.SwapRequest{
+		ShareIDOne:  txID,
+		QuantityOne: 1,
+		OwnerOne:    sender.AccountNumber(),
+		ShareIDTwo:  "fa9bb80247dd0f6b3e3f21153f49fbb297b9568e67e298c96dbd75d3a348efeb",
+		QuantityTwo: 2,
+		OwnerTwo:    receiver.AccountNumber(),
+		BeforeBlock: 100,
+	}
+	swap.Signature = hex.EncodeToString(sender.Sign(packSigned(t, swap)))
+	var actualSwap bitmark.SwapRequest
+	assert.NoError(t, utils.Unpack(packSigned(t, swap, swap.Signature), &actualSwap))
+	assert.Equal(t, *swap, actualSwap)
+}
+
+func TestUnpackInvalid(t *testing.T) {
+	share := &bitmark.ShareRequest{Link: txID, Quantity: 100}
+	packed := packSigned(t, share)
+
+	var nilShare *bitmark.ShareRequest
+	assert.EqualError(t, utils.Unpack(packed, nilShare), utils.ErrInvalidUnpackParam.Error())
+	assert.EqualError(t, utils.Unpack(packed, nil), utils.ErrInvalidUnpackParam.Error())
+
+	var actual bitmark.ShareRequest
+	assert.Error(t, utils.Unpack(packed[:10], &actual))
+	assert.EqualError(t, utils.Unpack(append(packed, 0x05, 0x01), &actual), "invalid Signature: "+utils.ErrTruncatedRecord.Error())
+	assert.EqualError(t, utils.Unpack(append(packed, 0x01, 0x02, 0x03), &actual), utils.ErrTrailingData.Error())
+
+	var issue bitmark.IssueRequest
+	assert.EqualError(t, utils.Unpack(packed, &issue), utils.ErrRecordTagMismatch.Error())
+
+	_, err := utils.RecordTag([]byte{0x01})
+	assert.EqualError(t, err, utils.ErrUnknownRecordTag.Error())
+}
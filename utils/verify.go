@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: ISC
+// Copyright (c) 2014-2020 Bitmark Inc.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/bitmark-inc/bitmark-sdk-go/account"
+)
+
+var (
+	ErrMissingSignature        = errors.New("record not signed")
+	ErrMissingCountersignature = errors.New("record not countersigned")
+	ErrInvalidSignature        = account.ErrInvalidSignature
+	ErrInvalidCountersignature = errors.New("invalid countersignature")
+)
+
+// VerifySignature repacks the record and checks the hex encoded signature against the signer's account number
+func VerifySignature(record Packable, signer, signature string) error {
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	return verify(record, signer, signature)
+}
+
+// VerifyCountersignature is VerifySignature for the countersignature of a record,
+// which covers the packed record including the owner's signature
+func VerifyCountersignature(record Packable, signer, countersignature string) error {
+	if countersignature == "" {
+		return ErrMissingCountersignature
+	}
+	if err := verify(record, signer, countersignature); err != nil {
+		if err == account.ErrInvalidSignature {
+			return ErrInvalidCountersignature
+		}
+		return err
+	}
+	return nil
+}
+
+func verify(record Packable, signer, signature string) error {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return account.ErrInvalidSignature
+	}
+
+	message, err := Pack(record)
+	if err != nil {
+		return err
+	}
+	return account.Verify(signer, message, sig)
+}